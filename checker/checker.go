@@ -0,0 +1,489 @@
+// Package checker finds type errors in a program without running it and
+// records where each name is declared and used.
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic is a problem found in the program.
+type Diagnostic struct {
+	Span     tree.Span
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Span.Start, d.Severity, d.Message)
+}
+
+type SymbolKind int
+
+const (
+	Variable SymbolKind = iota
+	Function
+)
+
+// Symbol is a declared name.
+type Symbol struct {
+	Name     string
+	Kind     SymbolKind
+	Datatype string      // INTEGER, STRING, or empty when not known
+	Decl     lexer.Piece // the name where it is declared
+	Node     tree.Node   // the declaring statement
+}
+
+// Use is an identifier referring to a symbol, including the name at the
+// declaration itself.
+type Use struct {
+	Span   tree.Span
+	Symbol *Symbol
+}
+
+// Info is what the checker learned about a program.
+type Info struct {
+	Diagnostics []Diagnostic
+	Symbols     []*Symbol // in the order they are declared
+	Uses        []Use
+}
+
+// SymbolAt returns the use found at pos.
+func (info *Info) SymbolAt(pos lexer.Position) (Use, bool) {
+	for _, use := range info.Uses {
+		if use.Span.Contains(pos) {
+			return use, true
+		}
+	}
+	return Use{}, false
+}
+
+// Check walks the program in source order. Like the evaluator it keeps a
+// single scope, a name refers to its latest declaration above the use.
+// Imports are read relative to the current directory.
+func Check(program *tree.Program) *Info {
+	return CheckFile(program, "")
+}
+
+// CheckFile checks the program of the file at path, the files it imports
+// are read relative to it. The names they export are declared at the
+// import, and their first error is reported there.
+func CheckFile(program *tree.Program, path string) *Info {
+	c := &checker{info: &Info{}, scope: map[string]*Symbol{}, path: path, loading: map[string]bool{}}
+	if key, err := filepath.Abs(path); path != "" && err == nil {
+		c.loading[key] = true
+	}
+	c.statements(program.Statements)
+	return c.info
+}
+
+type checker struct {
+	info    *Info
+	scope   map[string]*Symbol
+	path    string
+	loading map[string]bool // the files being checked, by absolute path
+}
+
+const (
+	integer = string(object.INTEGER_OBJ)
+	str     = string(object.STRING_OBJ)
+	boolean = string(object.BOOLEAN_OBJ)
+	array   = string(object.ARRAY_OBJ)
+	float   = string(object.FLOAT_OBJ)
+	hash    = string(object.HASH_OBJ)
+)
+
+func (c *checker) statements(stmts []tree.Stmt) {
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+func (c *checker) statement(stmt tree.Stmt) {
+	switch stmt := stmt.(type) {
+	case *tree.Declaration:
+		if stmt.Value == nil {
+			c.report(tree.SpanOf(stmt), Error, "Declaration of %s needs a value", stmt.Name.Value)
+		} else if value := c.expression(stmt.Value); value != "" && value != stmt.Datatype {
+			c.report(tree.SpanOf(stmt.Value), Error, "Cannot Assign %s to %s variable", value, stmt.Datatype)
+		}
+		c.declare(stmt.Name, Variable, stmt.Datatype, stmt)
+	case *tree.Input:
+		if stmt.Prompt != nil {
+			c.expression(stmt.Prompt)
+		}
+		c.declare(stmt.Variable.Piece, Variable, stmt.DataType, stmt)
+	case *tree.ExpressionStmt:
+		c.expression(stmt.Expression)
+	case *tree.PrintStmt:
+		for _, value := range stmt.Values {
+			c.expression(value)
+		}
+	case *tree.ImportStmt:
+		c.module(stmt)
+	case *tree.IfStmt:
+		c.condition(stmt.Condition, "If")
+		c.statements(stmt.Then.Statements)
+		for _, elseIf := range stmt.ElseIfs {
+			c.condition(elseIf.Condition, "If")
+			c.statements(elseIf.Then.Statements)
+		}
+		if stmt.Else != nil {
+			c.statements(stmt.Else.Statements)
+		}
+	case *tree.WhileStmt:
+		c.condition(stmt.Condition, "While")
+		c.statements(stmt.Body.Statements)
+	case *tree.ForStmt:
+		if count := c.expression(stmt.Count); count != "" && count != integer {
+			c.report(tree.SpanOf(stmt.Count), Error, "Expected Integer count in For loop, got %s", count)
+		}
+		c.statements(stmt.Body.Statements)
+	case *tree.EachStmt:
+		c.each(stmt)
+	case *tree.TryStmt:
+		c.statements(stmt.Body.Statements)
+		c.inner(func() {
+			c.declare(stmt.Name.Piece, Variable, hash, stmt)
+			c.statements(stmt.Handler.Statements)
+		})
+		if stmt.Finally != nil {
+			c.statements(stmt.Finally.Statements)
+		}
+	case *tree.RaiseStmt:
+		if kind := c.expression(stmt.Value); kind != "" && kind != str {
+			c.report(tree.SpanOf(stmt.Value), Error, "'eri' needs a String message, got %s", kind)
+		}
+	case *tree.Block:
+		c.statements(stmt.Statements)
+	case *tree.Function:
+		c.declare(stmt.Name, Function, "", stmt)
+		if stmt.Body != nil {
+			c.statements(stmt.Body.Statements)
+		}
+	case *tree.ReturnStmt:
+		if stmt.Value != nil {
+			c.expression(stmt.Value)
+		}
+	}
+}
+
+// each checks an ovvonrum loop. The names declared in its body, like the
+// names it binds, are gone after the loop.
+func (c *checker) each(stmt *tree.EachStmt) {
+	index, element := integer, ""
+	switch kind := c.expression(stmt.Iterable); kind {
+	case str:
+		element = str
+	case string(object.HASH_OBJ):
+		index, element = str, str
+		if stmt.Index != nil {
+			element = ""
+		}
+	case string(object.RANGE_OBJ):
+		element = integer
+	case "", string(object.ARRAY_OBJ):
+	default:
+		c.report(tree.SpanOf(stmt.Iterable), Error, "Cannot loop over %s, only over Strings, Arrays, Hashes and ranges", kind)
+	}
+	c.inner(func() {
+		if stmt.Index != nil {
+			c.declare(stmt.Index.Piece, Variable, index, stmt)
+		}
+		c.declare(stmt.Element.Piece, Variable, element, stmt)
+		c.statements(stmt.Body.Statements)
+	})
+}
+
+// inner checks a scope of its own, the names declared by check are gone
+// after it.
+func (c *checker) inner(check func()) {
+	outer := map[string]*Symbol{}
+	for name, symbol := range c.scope {
+		outer[name] = symbol
+	}
+	check()
+	c.scope = outer
+}
+
+// module checks the imported file and declares the names it exports.
+// Their declarations are in another file, so they point at the import.
+func (c *checker) module(stmt *tree.ImportStmt) {
+	span := tree.SpanOf(&stmt.Path)
+	path := evaluator.Resolve(c.path, stmt.Path.Value)
+	key, err := filepath.Abs(path)
+	if err != nil {
+		c.report(span, Error, "Cannot import %s: %s", path, err)
+		return
+	}
+	if c.loading[key] {
+		c.report(span, Error, "Import cycle through %s", path)
+		return
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		c.report(span, Error, "Cannot import %s: %s", path, err)
+		return
+	}
+	_, channel := lexer.CreateLexer(source, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		c.report(span, Error, "%s:%s", path, err)
+		return
+	}
+
+	c.loading[key] = true
+	module := &checker{info: &Info{}, scope: map[string]*Symbol{}, path: path, loading: c.loading}
+	module.statements(program.Statements)
+	delete(c.loading, key)
+	for _, found := range module.info.Diagnostics {
+		if found.Severity == Error {
+			c.report(span, Error, "%s:%s: %s", path, found.Span.Start, found.Message)
+			break
+		}
+	}
+	for _, exported := range module.info.Symbols {
+		if !evaluator.Exported(exported.Name) || module.scope[exported.Name] != exported {
+			continue
+		}
+		symbol := &Symbol{Name: exported.Name, Kind: exported.Kind, Datatype: exported.Datatype, Decl: stmt.Path.Piece, Node: stmt}
+		c.scope[symbol.Name] = symbol
+		c.info.Symbols = append(c.info.Symbols, symbol)
+	}
+}
+
+func (c *checker) condition(expr tree.Expr, statement string) {
+	if kind := c.expression(expr); kind != "" && kind != boolean {
+		c.report(tree.SpanOf(expr), Error, "Non Boolean Expression in %s Statement", statement)
+	}
+}
+
+// expression returns the type of expr, or an empty string when it is
+// not known because of an error reported already.
+func (c *checker) expression(expr tree.Expr) string {
+	switch expr := expr.(type) {
+	case *tree.Number:
+		return integer
+	case *tree.Float:
+		return float
+	case *tree.StringLiteral:
+		return str
+	case *tree.Boolean:
+		return boolean
+	case *tree.Identifier:
+		return c.use(expr.Piece)
+	case *tree.Assign:
+		value := c.expression(expr.Right)
+		symbol, ok := c.scope[expr.Left.Name]
+		if !ok {
+			c.report(tree.SpanOf(&expr.Left), Warning, "Assignment to undeclared variable %s", expr.Left.Name)
+			c.declare(expr.Left.Piece, Variable, value, expr)
+			return value
+		}
+		c.refer(expr.Left.Piece, symbol)
+		if value != "" && symbol.Datatype != "" && value != symbol.Datatype {
+			c.report(tree.SpanOf(expr.Right), Error, "Cannot Assign %s to %s variable", value, symbol.Datatype)
+		}
+		return value
+	case *tree.Array:
+		for _, element := range expr.Elements {
+			c.expression(element)
+		}
+		return array
+	case *tree.Access:
+		left, index := c.expression(expr.Left), c.expression(expr.Index)
+		if left != "" && left != str && left != array && left != hash {
+			c.report(tree.SpanOf(expr.Left), Error, "Cannot index %s", left)
+		}
+		if left == hash && index != "" && index != str {
+			c.report(tree.SpanOf(expr.Index), Error, "Key must be a String")
+		}
+		if (left == str || left == array) && index != "" && index != integer {
+			c.report(tree.SpanOf(expr.Index), Error, "Index must be an Integer")
+		}
+		if left == str {
+			return str
+		}
+		// the elements of an array and the values of a hash may be of
+		// any type
+		return ""
+	case *tree.Length:
+		if value := c.expression(expr.Value); value != "" && value != str && value != array && value != hash {
+			c.report(tree.SpanOf(expr.Value), Error, "Length can only be applied to Strings, Arrays and Hashes")
+		}
+		return integer
+	case *tree.Print:
+		return c.expression(expr.Value)
+	case *tree.Prefix:
+		return c.prefix(expr)
+	case *tree.Binary:
+		return c.binary(expr)
+	case *tree.Call:
+		return c.call(expr)
+	}
+	return ""
+}
+
+// call checks the arguments against the types the builtin accepts.
+func (c *checker) call(expr *tree.Call) string {
+	name := expr.Function.Name
+	builtin, ok := evaluator.Builtins[name]
+	if !ok {
+		c.report(tree.SpanOf(&expr.Function), Error, "Unknown function %s", name)
+		for _, arg := range expr.Args {
+			c.expression(arg)
+		}
+		return ""
+	}
+	if len(expr.Args) != len(builtin.Params) {
+		c.report(tree.SpanOf(expr), Error, "%s takes %d arguments, got %d", name, len(builtin.Params), len(expr.Args))
+	}
+	for n, arg := range expr.Args {
+		if n < len(builtin.Params) && builtin.Params[n] == object.ERROR_OBJ {
+			// the argument is expected to fail, like in assert_error
+			reported := len(c.info.Diagnostics)
+			c.expression(arg)
+			c.info.Diagnostics = c.info.Diagnostics[:reported]
+			continue
+		}
+		kind := c.expression(arg)
+		if n >= len(builtin.Params) {
+			continue
+		}
+		want := string(builtin.Params[n])
+		if kind != "" && want != "" && kind != want {
+			c.report(tree.SpanOf(arg), Error, "Argument %d of %s must be %s, got %s", n+1, name, want, kind)
+		}
+	}
+	return string(builtin.Result)
+}
+
+func (c *checker) prefix(expr *tree.Prefix) string {
+	right := c.expression(expr.Right)
+	if expr.Operator.Kind == lexer.Bang {
+		if right != "" && right != boolean {
+			c.report(tree.SpanOf(expr), Error, "Invalid Operand Type %s for %s", right, expr.Operator.Value)
+		}
+		return boolean
+	}
+	if right != "" && !numeric(right) {
+		c.report(tree.SpanOf(expr), Error, "Invalid Operand Type %s for %s", right, expr.Operator.Value)
+	}
+	if right == float {
+		return float
+	}
+	return integer
+}
+
+// binary mirrors the operand types accepted by the evaluator.
+func (c *checker) binary(expr *tree.Binary) string {
+	left, right := c.expression(expr.Left), c.expression(expr.Right)
+	if left == "" || right == "" {
+		return c.result(expr.Operator.Kind, left, right)
+	}
+	valid := false
+	switch expr.Operator.Kind {
+	case lexer.Plus:
+		valid = left != boolean && right != boolean
+	case lexer.Minus, lexer.Star, lexer.Slash, lexer.Percent,
+		lexer.Less, lexer.Greater, lexer.LessEqual, lexer.GreaterEqual:
+		valid = numeric(left) && numeric(right)
+	case lexer.And, lexer.Or:
+		valid = left == boolean && right == boolean
+	case lexer.Equal, lexer.NotEqual:
+		valid = true
+	}
+	if !valid {
+		c.report(tree.SpanOf(expr), Error, "Invalid Operand Types %s and %s for %s", left, right, expr.Operator.Value)
+		return c.result(expr.Operator.Kind, "", "")
+	}
+	return c.result(expr.Operator.Kind, left, right)
+}
+
+// result is the type produced by an operator, comparisons are always
+// Boolean even when the operands are not known.
+func (c *checker) result(kind lexer.PieceType, left, right string) string {
+	switch kind {
+	case lexer.Equal, lexer.NotEqual, lexer.Less, lexer.Greater,
+		lexer.LessEqual, lexer.GreaterEqual, lexer.And, lexer.Or:
+		return boolean
+	case lexer.Plus:
+		if left == "" || right == "" {
+			return ""
+		}
+		if left == str || right == str {
+			return str
+		}
+		fallthrough
+	case lexer.Minus, lexer.Star, lexer.Slash, lexer.Percent:
+		if left == float || right == float {
+			return float
+		}
+		return integer
+	}
+	return ""
+}
+
+// numeric reports whether values of the type take arithmetic, Integers
+// and Floats mix.
+func numeric(datatype string) bool {
+	return datatype == integer || datatype == float
+}
+
+func (c *checker) declare(name lexer.Piece, kind SymbolKind, datatype string, node tree.Node) {
+	symbol := &Symbol{Name: name.Value, Kind: kind, Datatype: datatype, Decl: name, Node: node}
+	c.scope[name.Value] = symbol
+	c.info.Symbols = append(c.info.Symbols, symbol)
+	c.refer(name, symbol)
+}
+
+func (c *checker) use(name lexer.Piece) string {
+	symbol, ok := c.scope[name.Value]
+	if !ok {
+		c.report(tree.Span{Start: name.Pos, End: name.End}, Error, "Unknown identifier %s", name.Value)
+		return ""
+	}
+	c.refer(name, symbol)
+	return symbol.Datatype
+}
+
+func (c *checker) refer(name lexer.Piece, symbol *Symbol) {
+	if name.Pos.IsValid() {
+		c.info.Uses = append(c.info.Uses, Use{Span: tree.Span{Start: name.Pos, End: name.End}, Symbol: symbol})
+	}
+}
+
+func (c *checker) report(span tree.Span, severity Severity, format string, args ...any) {
+	c.info.Diagnostics = append(c.info.Diagnostics, Diagnostic{
+		Span:     span,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
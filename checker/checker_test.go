@@ -0,0 +1,169 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+func check(input string) *Info {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return Check(parser.Parse(channel, false))
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`yen a = 1; sol s = "x"; a + 1 sollu; s + a sollu; s[0] sollu; s neelam sollu;`, nil},
+		{`yen a = "x";`, []string{"1:9: error: Cannot Assign STRING to INTEGER variable"}},
+		{`yen a = 1; a = "x";`, []string{"1:16: error: Cannot Assign STRING to INTEGER variable"}},
+		{`b sollu;`, []string{"1:1: error: Unknown identifier b"}},
+		{`b = 1; b sollu;`, []string{"1:1: warning: Assignment to undeclared variable b"}},
+		{`sol s = "x"; s - 1 sollu;`, []string{"1:14: error: Invalid Operand Types STRING and INTEGER for -"}},
+		{`aam + 1 sollu;`, []string{"1:1: error: Invalid Operand Types BOOLEAN and INTEGER for +"}},
+		{`1 && aam sollu;`, []string{"1:1: error: Invalid Operand Types INTEGER and BOOLEAN for &&"}},
+		{`yen a = 1; a == -"x" sollu; aam == !a sollu;`,
+			[]string{"1:17: error: Invalid Operand Type STRING for -", "1:36: error: Invalid Operand Type INTEGER for !"}},
+		{`yen a = 1; a endral { }`, []string{"1:12: error: Non Boolean Expression in If Statement"}},
+		{`yen a = 1; illai endral { } illana a endral { a sollu; } illana a > 0 endral { } illana { b sollu; }`, []string{
+			"1:36: error: Non Boolean Expression in If Statement", "1:91: error: Unknown identifier b"}},
+		{`"x" varaikkum { }`, []string{"1:1: error: Non Boolean Expression in While Statement"}},
+		{`aam murai { }`, []string{"1:1: error: Expected Integer count in For loop, got BOOLEAN"}},
+		{`yen a = 1; a[0] sollu; a neelam sollu;`, []string{"1:12: error: Cannot index INTEGER", "1:24: error: Length can only be applied to Strings, Arrays and Hashes"}},
+		{`varisai a = split("a,b", ","); a[0] + a neelam sollu; join(a, "-") sollu; [1, "x"][1] sollu;`, nil},
+		{`varisai a = "x"; join("a", 1) sollu;`, []string{
+			"1:13: error: Cannot Assign STRING to ARRAY variable",
+			"1:23: error: Argument 1 of join must be ARRAY, got STRING",
+			"1:28: error: Argument 2 of join must be STRING, got INTEGER"}},
+		{`thasamam f = 1.5; yen n = 2; f = f * n + -f; n < f sollu; thasamam r = sqrt(n); yen c = ceil(r);`, nil},
+		{`yen n = 2.5; thasamam f = 1 + 1; 1.5 % "x" sollu;`, []string{
+			"1:9: error: Cannot Assign FLOAT to INTEGER variable",
+			"1:27: error: Cannot Assign INTEGER to FLOAT variable",
+			"1:34: error: Invalid Operand Types FLOAT and STRING for %"}},
+		{`agarathi h = json_decode("{}"); h["a"] + h neelam sollu; h[0] sollu; json_encode(h)[0] sollu;`, []string{"1:60: error: Key must be a String"}},
+		{`sol s = "x"; s["0"] sollu;`, []string{"1:16: error: Index must be an Integer"}},
+		{`yen a;`, []string{"1:1: error: Declaration of a needs a value"}},
+		{`assert_equal(1, "x"); assert_true(1 == 1); assert_error("x" - 1, "Mismatch");`, nil},
+		{`assert_true(1); check(1 + aam);`, []string{
+			"1:13: error: Argument 1 of assert_true must be BOOLEAN, got INTEGER",
+			"1:17: error: Unknown function check",
+			"1:23: error: Invalid Operand Types INTEGER and BOOLEAN for +"}},
+		{`assert_true(aam, aam);`, []string{"1:1: error: assert_true takes 1 arguments, got 2"}},
+		{`"ab" ovvonrum i, l { i + 1 sollu; l + "x" sollu; } range(0, 2) ovvonrum n { n * 2 sollu; } [1] ovvonrum x { x sollu; }`, nil},
+		{`"ab" ovvonrum l { l - 1 sollu; } l sollu; 5 ovvonrum x { }`, []string{
+			"1:19: error: Invalid Operand Types STRING and INTEGER for -",
+			"1:34: error: Unknown identifier l",
+			"1:43: error: Cannot loop over INTEGER, only over Strings, Arrays, Hashes and ranges"}},
+		{`muyarchi { "x" eri; } pidi e { e["message"] + e["line"] sollu; } iruthiyaga { "done" sollu; }`, nil},
+		{`muyarchi { 1 eri; } pidi e { } e sollu; muyarchi { } pidi e { yen n = e; }`, []string{
+			"1:12: error: 'eri' needs a String message, got INTEGER",
+			"1:32: error: Unknown identifier e",
+			"1:71: error: Cannot Assign HASH to INTEGER variable"}},
+		// an unknown name is reported once, not again by the operators using it
+		{`c + 1 - 2 sollu;`, []string{"1:1: error: Unknown identifier c"}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for _, diagnostic := range check(tt.input).Diagnostics {
+			got = append(got, diagnostic.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("%s\ngot  %q\nwant %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	info := check("yen a = 1;\nsol s kodu;\na = a + s neelam;\nyen a = 2;\na sollu;")
+	names := []string{}
+	for _, symbol := range info.Symbols {
+		names = append(names, symbol.Name+":"+symbol.Datatype+"@"+symbol.Decl.Pos.String())
+	}
+	if got := strings.Join(names, " "); got != "a:INTEGER@1:5 s:STRING@2:5 a:INTEGER@4:5" {
+		t.Errorf("symbols %s", got)
+	}
+	tests := []struct {
+		line, column int
+		declared     string
+	}{
+		{3, 1, "1:5"}, // assignment
+		{3, 5, "1:5"}, // use
+		{3, 9, "2:5"},
+		{5, 1, "4:5"}, // refers to the latest declaration
+		{1, 5, "1:5"}, // the declaration itself
+	}
+	for _, tt := range tests {
+		pos := position(info, tt.line, tt.column)
+		use, ok := info.SymbolAt(pos)
+		if !ok {
+			t.Errorf("%d:%d: no symbol", tt.line, tt.column)
+			continue
+		}
+		if got := use.Symbol.Decl.Pos.String(); got != tt.declared {
+			t.Errorf("%d:%d: declared at %s, want %s", tt.line, tt.column, got, tt.declared)
+		}
+	}
+	if _, ok := info.SymbolAt(position(info, 2, 7)); ok {
+		t.Errorf("found a symbol on a keyword")
+	}
+}
+
+// position finds the offset of line:column among the uses, or returns a
+// position between them.
+func position(info *Info, line, column int) lexer.Position {
+	for _, use := range info.Uses {
+		if use.Span.Start.Line == line && use.Span.Start.Column == column {
+			return use.Span.Start
+		}
+	}
+	return lexer.Position{Offset: -1, Line: line, Column: column}
+}
+
+func TestFunctions(t *testing.T) {
+	add := &tree.Function{Name: lexer.Piece{Kind: lexer.Identifier, Value: "add"}, Body: &tree.Block{}}
+	info := Check(&tree.Program{Statements: []tree.Stmt{add}})
+	if len(info.Symbols) != 1 || info.Symbols[0].Kind != Function || info.Symbols[0].Name != "add" {
+		t.Errorf("symbols %v", info.Symbols)
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"lib.n":   `"more.n" irakkumathi; sol greeting = "vanakkam"; yen _count = 1;`,
+		"more.n":  `yen size = 2;`,
+		"cycle.n": `"main.n" irakkumathi;`,
+		"bad.n":   `yen a = "x";`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := `"lib.n" irakkumathi; greeting + size sollu; _count sollu; "cycle.n" irakkumathi; "bad.n" irakkumathi; "none.n" irakkumathi;`
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	info := CheckFile(parser.Parse(channel, false), filepath.Join(dir, "main.n"))
+	got := []string{}
+	for _, diagnostic := range info.Diagnostics {
+		got = append(got, strings.ReplaceAll(filepath.ToSlash(diagnostic.String()), filepath.ToSlash(dir), "DIR"))
+	}
+	expected := []string{
+		"1:45: error: Unknown identifier _count",
+		"1:59: error: DIR/cycle.n:1:1: Import cycle through DIR/main.n",
+		"1:82: error: DIR/bad.n:1:9: Cannot Assign STRING to INTEGER variable",
+		"1:103: error: Cannot import DIR/none.n: no such file or directory",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got  %q\nwant %q", got, expected)
+	}
+	use, ok := info.SymbolAt(position(info, 1, 22))
+	if !ok || use.Symbol.Datatype != "STRING" || use.Symbol.Decl.Pos.Column != 1 {
+		t.Errorf("greeting should be a STRING declared at the import, got %v", use.Symbol)
+	}
+}
@@ -0,0 +1,206 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpTrue
+	OpFalse
+
+	OpGetGlobal
+	OpSetGlobal
+	OpDeclare
+	OpInput
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpGreater
+	OpLessEqual
+	OpGreaterEqual
+	OpAnd
+	OpOr
+
+	OpMinus
+	OpBang
+	OpIndex
+	OpLength
+
+	OpPrint
+
+	OpJump
+	OpJumpIfFalse
+	OpSkipIfNotBool
+	OpForPrep
+	OpForNext
+	OpPop
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+
+	// global index
+	OpGetGlobal: {"OpGetGlobal", []int{2}},
+	OpSetGlobal: {"OpSetGlobal", []int{2}},
+	// global index, datatype
+	OpDeclare: {"OpDeclare", []int{2, 1}},
+	// global index, datatype, 1 when a prompt is on the stack
+	OpInput: {"OpInput", []int{2, 1, 1}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpMod:          {"OpMod", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpLess:         {"OpLess", []int{}},
+	OpGreater:      {"OpGreater", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpAnd:          {"OpAnd", []int{}},
+	OpOr:           {"OpOr", []int{}},
+
+	OpMinus:  {"OpMinus", []int{}},
+	OpBang:   {"OpBang", []int{}},
+	OpIndex:  {"OpIndex", []int{}},
+	OpLength: {"OpLength", []int{}},
+
+	// value count, newline
+	OpPrint: {"OpPrint", []int{1, 1}},
+
+	// jump target
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpIfFalse:   {"OpJumpIfFalse", []int{2}},
+	OpSkipIfNotBool: {"OpSkipIfNotBool", []int{2}},
+	OpForPrep:       {"OpForPrep", []int{}},
+	OpForNext:       {"OpForNext", []int{2}},
+	OpPop:           {"OpPop", []int{}},
+}
+
+// Datatype operands of OpDeclare and OpInput
+const (
+	TypeInteger byte = iota
+	TypeString
+)
+
+var datatypes = map[string]byte{
+	"INTEGER": TypeInteger,
+	"STRING":  TypeString,
+}
+
+// DatatypeName maps a datatype operand back to the name used by the tree.
+func DatatypeName(t byte) string {
+	for name, b := range datatypes {
+		if b == t {
+			return name
+		}
+	}
+	return ""
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+	return instruction
+}
+
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.format(def, operands))
+		i += 1 + read
+	}
+	return out.String()
+}
+
+func (ins Instructions) format(def *Definition, operands []int) string {
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	case 3:
+		return fmt.Sprintf("%s %d %d %d", def.Name, operands[0], operands[1], operands[2])
+	}
+	return fmt.Sprintf("ERROR: unhandled operand count for %s", def.Name)
+}
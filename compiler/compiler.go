@@ -0,0 +1,369 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Bytecode is the output of the compiler, ready to be run by the vm.
+type Bytecode struct {
+	Instructions Instructions
+	Constants    []object.Object
+	Globals      []string // names of the global slots, by index
+}
+
+type Compiler struct {
+	instructions Instructions
+	constants    []object.Object
+	symbols      *SymbolTable
+
+	integers map[int64]int
+	strings  map[string]int
+	loops    []*loop // the loops around the statement being compiled
+
+	// err is the first operand that did not fit its instruction, Compile
+	// returns it once the program is compiled.
+	err error
+}
+
+// loop is a loop being compiled. Its niruthu jumps are patched once the
+// end of the loop is known.
+type loop struct {
+	next    int  // where a thodar jumps to
+	counter bool // a murai loop keeps its counter on the stack
+	breaks  []int
+}
+
+func New() *Compiler {
+	return &Compiler{
+		symbols:  NewSymbolTable(),
+		integers: map[int64]int{},
+		strings:  map[string]int{},
+	}
+}
+
+var binaryOps = map[lexer.PieceType]Opcode{
+	lexer.Plus:         OpAdd,
+	lexer.Minus:        OpSub,
+	lexer.Star:         OpMul,
+	lexer.Slash:        OpDiv,
+	lexer.Percent:      OpMod,
+	lexer.Equal:        OpEqual,
+	lexer.NotEqual:     OpNotEqual,
+	lexer.Less:         OpLess,
+	lexer.Greater:      OpGreater,
+	lexer.LessEqual:    OpLessEqual,
+	lexer.GreaterEqual: OpGreaterEqual,
+	lexer.And:          OpAnd,
+	lexer.Or:           OpOr,
+}
+
+var prefixOps = map[lexer.PieceType]Opcode{
+	lexer.Minus: OpMinus,
+	lexer.Bang:  OpBang,
+}
+
+func (c *Compiler) Compile(node tree.Node) error {
+	switch node := node.(type) {
+
+	// Statements
+	case *tree.Program:
+		for _, stmt := range node.Statements {
+			if err := c.Compile(stmt); err != nil {
+				return err
+			}
+		}
+	case *tree.Block:
+		for _, stmt := range node.Statements {
+			if err := c.Compile(stmt); err != nil {
+				return err
+			}
+		}
+	case *tree.PrintStmt:
+		if len(node.Values) > 255 {
+			return fmt.Errorf("cannot compile a print of %d values, the vm prints at most 255", len(node.Values))
+		}
+		for _, value := range node.Values {
+			if err := c.compileExpression(value); err != nil {
+				return err
+			}
+		}
+		newline := 0
+		if node.Newline() {
+			newline = 1
+		}
+		c.emit(OpPrint, len(node.Values), newline)
+	case *tree.ImportStmt:
+		return fmt.Errorf("cannot import %s, the vm runs a single file", node.Path.Value)
+	case *tree.Input:
+		datatype, ok := datatypes[node.DataType]
+		if !ok {
+			return fmt.Errorf("invalid input type %s", node.DataType)
+		}
+		prompt := 0
+		if node.Prompt != nil {
+			if err := c.compileExpression(node.Prompt); err != nil {
+				return err
+			}
+			prompt = 1
+		}
+		c.emit(OpInput, c.symbols.Resolve(node.Variable.Name), int(datatype), prompt)
+	case *tree.Declaration:
+		datatype, ok := datatypes[node.Datatype]
+		if !ok {
+			return fmt.Errorf("invalid datatype %s", node.Datatype)
+		}
+		if node.Value == nil {
+			return fmt.Errorf("declaration of %s has no value", node.Name.Value)
+		}
+		if err := c.compileExpression(node.Value); err != nil {
+			return err
+		}
+		c.emit(OpDeclare, c.symbols.Resolve(node.Name.Value), int(datatype))
+	case *tree.IfStmt:
+		return c.compileIf(node)
+	case *tree.WhileStmt:
+		return c.compileWhile(node)
+	case *tree.ForStmt:
+		return c.compileFor(node)
+	case *tree.BranchStmt:
+		return c.compileBranch(node)
+	case *tree.EachStmt:
+		return fmt.Errorf("cannot compile an ovvonrum loop, the vm has no scopes yet")
+	case *tree.TryStmt:
+		return fmt.Errorf("cannot compile muyarchi, the vm has no error handling yet")
+	case *tree.RaiseStmt:
+		return fmt.Errorf("cannot compile eri, the vm has no error handling yet")
+	case *tree.ExpressionStmt:
+		// only assignments and calls have an effect as statements
+		switch expr := node.Expression.(type) {
+		case *tree.Assign:
+			if err := c.compileExpression(expr.Right); err != nil {
+				return err
+			}
+			c.emit(OpSetGlobal, c.symbols.Resolve(expr.Left.Name))
+		case *tree.Call:
+			return c.compileExpression(expr)
+		}
+
+	default:
+		return fmt.Errorf("cannot compile node %T", node)
+	}
+	return c.err
+}
+
+// compileIf tests the conditions in order, a false one jumps to the next
+// branch and a block that ran jumps past the others. A condition that is
+// not a Boolean skips the whole statement.
+func (c *Compiler) compileIf(stmt *tree.IfStmt) error {
+	branches := []*tree.ElseIf{{Condition: stmt.Condition, Then: stmt.Then}}
+	branches = append(branches, stmt.ElseIfs...)
+	ends := []int{}
+	for n, branch := range branches {
+		if err := c.compileExpression(branch.Condition); err != nil {
+			return err
+		}
+		ends = append(ends, c.emit(OpSkipIfNotBool, 0xFFFF))
+		jumpNext := c.emit(OpJumpIfFalse, 0xFFFF)
+		if err := c.Compile(branch.Then); err != nil {
+			return err
+		}
+		if n < len(branches)-1 || stmt.Else != nil {
+			ends = append(ends, c.emit(OpJump, 0xFFFF))
+		}
+		c.patch(jumpNext, len(c.instructions))
+	}
+	if stmt.Else != nil {
+		if err := c.Compile(stmt.Else); err != nil {
+			return err
+		}
+	}
+	for _, end := range ends {
+		c.patch(end, len(c.instructions))
+	}
+	return nil
+}
+
+func (c *Compiler) compileWhile(stmt *tree.WhileStmt) error {
+	start := len(c.instructions)
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(OpJumpIfFalse, 0xFFFF)
+	if err := c.compileLoopBody(stmt.Body, &loop{next: start}); err != nil {
+		return err
+	}
+	c.emit(OpJump, start)
+	c.patch(exit, len(c.instructions))
+	c.patchBreaks()
+	return nil
+}
+
+func (c *Compiler) compileFor(stmt *tree.ForStmt) error {
+	if err := c.compileExpression(stmt.Count); err != nil {
+		return err
+	}
+	c.emit(OpForPrep)
+	start := c.emit(OpForNext, 0xFFFF)
+	if err := c.compileLoopBody(stmt.Body, &loop{next: start, counter: true}); err != nil {
+		return err
+	}
+	c.emit(OpJump, start)
+	c.patch(start, len(c.instructions))
+	c.patchBreaks()
+	return nil
+}
+
+func (c *Compiler) compileLoopBody(body *tree.Block, l *loop) error {
+	c.loops = append(c.loops, l)
+	return c.Compile(body)
+}
+
+// patchBreaks points the niruthu jumps of the innermost loop to the end
+// of it, which was just compiled.
+func (c *Compiler) patchBreaks() {
+	l := c.loops[len(c.loops)-1]
+	c.loops = c.loops[:len(c.loops)-1]
+	for _, jump := range l.breaks {
+		c.patch(jump, len(c.instructions))
+	}
+}
+
+func (c *Compiler) compileBranch(stmt *tree.BranchStmt) error {
+	if len(c.loops) == 0 {
+		return fmt.Errorf("'%s' can only be used inside a loop", stmt.Piece.Value)
+	}
+	l := c.loops[len(c.loops)-1]
+	if !stmt.Break() {
+		c.emit(OpJump, l.next)
+		return nil
+	}
+	if l.counter {
+		c.emit(OpPop)
+	}
+	l.breaks = append(l.breaks, c.emit(OpJump, 0xFFFF))
+	return nil
+}
+
+func (c *Compiler) compileExpression(expr tree.Expr) error {
+	switch expr := expr.(type) {
+	case *tree.Number:
+		c.emit(OpConstant, c.integer(expr.Value))
+	case *tree.StringLiteral:
+		c.emit(OpConstant, c.string(expr.Value))
+	case *tree.Boolean:
+		if expr.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+	case *tree.Identifier:
+		c.emit(OpGetGlobal, c.symbols.Resolve(expr.Name))
+	case *tree.Access:
+		if err := c.compileExpression(expr.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(expr.Index); err != nil {
+			return err
+		}
+		c.emit(OpIndex)
+	case *tree.Length:
+		if err := c.compileExpression(expr.Value); err != nil {
+			return err
+		}
+		c.emit(OpLength)
+	case *tree.Prefix:
+		op, ok := prefixOps[expr.Operator.Kind]
+		if !ok {
+			return fmt.Errorf("unknown prefix operator '%s'", expr.Operator.Value)
+		}
+		if err := c.compileExpression(expr.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+	case *tree.Binary:
+		op, ok := binaryOps[expr.Operator.Kind]
+		if !ok {
+			return fmt.Errorf("unknown operator '%s'", expr.Operator.Value)
+		}
+		if err := c.compileExpression(expr.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(expr.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+	case *tree.Call:
+		return fmt.Errorf("cannot call %s, the vm has no builtin functions yet", expr.Function.Name)
+	case *tree.Array:
+		return fmt.Errorf("cannot compile an array, the vm has no arrays yet")
+	case *tree.Float:
+		return fmt.Errorf("cannot compile %s, the vm has no floats yet", expr)
+	default:
+		return fmt.Errorf("cannot compile expression %T", expr)
+	}
+	return nil
+}
+
+// integer and string intern literals so that each distinct value
+// occupies a single slot in the constant pool.
+
+func (c *Compiler) integer(value int64) int {
+	if index, ok := c.integers[value]; ok {
+		return index
+	}
+	index := c.addConstant(&object.Integer{Value: value})
+	c.integers[value] = index
+	return index
+}
+
+func (c *Compiler) string(value string) int {
+	if index, ok := c.strings[value]; ok {
+		return index
+	}
+	index := c.addConstant(&object.String{Value: value})
+	c.strings[value] = index
+	return index
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	c.check(op, operands)
+	pos := len(c.instructions)
+	c.instructions = append(c.instructions, Make(op, operands...)...)
+	return pos
+}
+
+// patch replaces the first operand of the jump instruction at pos.
+func (c *Compiler) patch(pos int, target int) {
+	op := Opcode(c.instructions[pos])
+	c.check(op, []int{target})
+	copy(c.instructions[pos:], Make(op, target))
+}
+
+// check records an operand too large for its width, a jump past the
+// first 65536 bytes or a constant or global beyond the first 65536, which
+// Make would otherwise cut down to a wrong one.
+func (c *Compiler) check(op Opcode, operands []int) {
+	def := definitions[op]
+	for n, operand := range operands {
+		if limit := 1<<(8*def.OperandWidths[n]) - 1; operand > limit && c.err == nil {
+			c.err = fmt.Errorf("cannot compile %s %d, the vm allows at most %d, the program is too large", def.Name, operand, limit)
+		}
+	}
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.instructions,
+		Constants:    c.constants,
+		Globals:      c.symbols.Names(),
+	}
+}
@@ -0,0 +1,66 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+func TestConstantPool(t *testing.T) {
+	input := `yen a = 10; a = a + 10; sol s = "x"; s = s + "x"; a sollu;`
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	comp := New()
+	if err := comp.Compile(parser.Parse(channel, false)); err != nil {
+		t.Fatal(err)
+	}
+	bytecode := comp.Bytecode()
+	if len(bytecode.Constants) != 2 {
+		t.Errorf("expected 2 interned constants, got %d", len(bytecode.Constants))
+	}
+	if len(bytecode.Globals) != 2 || bytecode.Globals[0] != "a" || bytecode.Globals[1] != "s" {
+		t.Errorf("unexpected globals %v", bytecode.Globals)
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	ins := Instructions{}
+	ins = append(ins, Make(OpConstant, 1)...)
+	ins = append(ins, Make(OpDeclare, 2, int(TypeString))...)
+	ins = append(ins, Make(OpJump, 65535)...)
+	ins = append(ins, Make(OpPrint, 2, 1)...)
+	ins = append(ins, Make(OpInput, 1, int(TypeInteger), 1)...)
+
+	expected := `0000 OpConstant 1
+0003 OpDeclare 2 1
+0007 OpJump 65535
+0010 OpPrint 2 1
+0013 OpInput 1 0 1
+`
+	if ins.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, ins.String())
+	}
+}
+
+func TestOperandLimits(t *testing.T) {
+	c := New()
+	for n := 0; n <= 0xFFFF; n++ {
+		c.emit(OpConstant, c.integer(int64(n)))
+	}
+	if c.err != nil {
+		t.Fatalf("65536 constants should fit, got %v", c.err)
+	}
+	c.emit(OpConstant, c.integer(-1))
+	want := "cannot compile OpConstant 65536, the vm allows at most 65535, the program is too large"
+	if c.err == nil || c.err.Error() != want {
+		t.Errorf("got %v, want %s", c.err, want)
+	}
+
+	// a jump patched past 0xFFFF
+	c = New()
+	c.patch(c.emit(OpJump, 0xFFFF), 0x10000)
+	if err := c.Compile(&tree.Program{}); err == nil || err.Error() != "cannot compile OpJump 65536, the vm allows at most 65535, the program is too large" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package compiler
+
+// SymbolTable assigns every variable name a global slot. The language has
+// a single scope, so a name keeps the same slot for the whole program.
+type SymbolTable struct {
+	store map[string]int
+	names []string
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: map[string]int{}}
+}
+
+// Resolve returns the slot of name, defining it on first use.
+func (s *SymbolTable) Resolve(name string) int {
+	if index, ok := s.store[name]; ok {
+		return index
+	}
+	index := len(s.names)
+	s.store[name] = index
+	s.names = append(s.names, name)
+	return index
+}
+
+func (s *SymbolTable) Names() []string {
+	return s.names
+}
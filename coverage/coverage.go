@@ -0,0 +1,152 @@
+// Package coverage records which statements of a program ran and which
+// ways its endral/illana branches went, and reports it as LCOV and as
+// annotated HTML source.
+package coverage
+
+import (
+	"strings"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Coverage is a hook counting the statements and branches of the files
+// added to it.
+type Coverage struct {
+	evaluator.NopHook
+
+	files      []*File
+	statements map[tree.Stmt]*int64
+	branches   map[tree.Node]*Branch
+}
+
+// File is the coverage of one source file.
+type File struct {
+	Path   string
+	Source []byte
+
+	statements map[tree.Stmt]*int64
+	branches   []*Branch
+}
+
+// Branch counts the times an if statement or one of its else ifs ran its
+// endral block and the times it did not, whether or not an illana part
+// follows.
+type Branch struct {
+	Line int
+	Then int64
+	Else int64
+}
+
+// Line is the coverage of one line of source.
+type Line struct {
+	Number int
+	Text   string
+	// Hits is the most times a statement of the line ran, -1 when there
+	// is no statement on the line.
+	Hits     int64
+	Branches []Branch
+}
+
+func New() *Coverage {
+	return &Coverage{
+		statements: map[tree.Stmt]*int64{},
+		branches:   map[tree.Node]*Branch{},
+	}
+}
+
+// Add makes the statements of the program count, before it runs.
+func (c *Coverage) Add(path string, source []byte, program *tree.Program) *File {
+	file := &File{Path: path, Source: source, statements: map[tree.Stmt]*int64{}}
+	branch := func(node tree.Node) {
+		branch := &Branch{Line: tree.SpanOf(node).Start.Line}
+		file.branches = append(file.branches, branch)
+		c.branches[node] = branch
+	}
+	tree.Inspect(program, func(node tree.Node) bool {
+		if elseIf, ok := node.(*tree.ElseIf); ok {
+			branch(elseIf)
+			return true
+		}
+		stmt, ok := node.(tree.Stmt)
+		if !ok || node == nil {
+			return node != nil
+		}
+		switch stmt := stmt.(type) {
+		case *tree.Program, *tree.Block:
+			return true
+		case *tree.IfStmt:
+			branch(stmt)
+		}
+		count := new(int64)
+		file.statements[stmt] = count
+		c.statements[stmt] = count
+		return true
+	})
+	c.files = append(c.files, file)
+	return file
+}
+
+// Files returns the files in the order they were added.
+func (c *Coverage) Files() []*File {
+	return c.files
+}
+
+func (c *Coverage) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	if count, ok := c.statements[stmt]; ok {
+		*count++
+	}
+}
+
+func (c *Coverage) OnBranch(node tree.Node, taken bool) {
+	branch, ok := c.branches[node]
+	if !ok {
+		return
+	}
+	if taken {
+		branch.Then++
+	} else {
+		branch.Else++
+	}
+}
+
+// Lines returns every line of the file with its coverage.
+func (f *File) Lines() []Line {
+	texts := strings.Split(strings.TrimSuffix(string(f.Source), "\n"), "\n")
+	lines := make([]Line, len(texts))
+	for i, text := range texts {
+		lines[i] = Line{Number: i + 1, Text: text, Hits: -1}
+	}
+	for stmt, count := range f.statements {
+		line := tree.SpanOf(stmt).Start.Line
+		if line < 1 || line > len(lines) {
+			continue
+		}
+		lines[line-1].Hits = max(lines[line-1].Hits, *count)
+	}
+	for _, branch := range f.branches {
+		if branch.Line >= 1 && branch.Line <= len(lines) {
+			// an else if is not a statement, its line ran when its
+			// condition was tested
+			line := &lines[branch.Line-1]
+			line.Hits = max(line.Hits, branch.Then+branch.Else)
+			line.Branches = append(line.Branches, *branch)
+		}
+	}
+	return lines
+}
+
+// Covered reports whether the line ran and took every way of its
+// branches, lines without statements count as covered.
+func (l Line) Covered() bool {
+	if l.Hits == 0 {
+		return false
+	}
+	for _, branch := range l.Branches {
+		if branch.Then == 0 || branch.Else == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+)
+
+const program = `yen i = 0;
+i < 3 varaikkum {
+    i % 2 == 0 endral {
+        "even" sollu;
+    } illana i == 5 endral {
+        "five" sollu;
+    }
+    i = i + 1;
+}
+i > 5 endral {
+    "big" sollu;
+}
+`
+
+func cover(t *testing.T) *Coverage {
+	t.Helper()
+	_, channel := lexer.CreateLexer([]byte(program), false)
+	ast := parser.Parse(channel, false)
+	c := New()
+	c.Add("main.n", []byte(program), ast)
+	interpreter := &evaluator.Interpreter{Out: &bytes.Buffer{}, Hook: c}
+	if err := interpreter.Run(ast, object.NewEnvironment()); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLines(t *testing.T) {
+	lines := []string{}
+	for _, line := range cover(t).Files()[0].Lines() {
+		text := fmt.Sprintf("%d:%d", line.Number, line.Hits)
+		for _, branch := range line.Branches {
+			text += fmt.Sprintf(" %d/%d", branch.Then, branch.Else)
+		}
+		if !line.Covered() {
+			text += " not covered"
+		}
+		lines = append(lines, text)
+	}
+	expected := []string{
+		"1:1", "2:1", "3:3 2/1", "4:2", "5:1 0/1 not covered", "6:0 not covered",
+		"7:-1", "8:3", "9:-1", "10:1 0/1 not covered", "11:0 not covered", "12:-1",
+	}
+	if strings.Join(lines, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("got %s\nwant %s", strings.Join(lines, ", "), strings.Join(expected, ", "))
+	}
+}
+
+func TestLCOV(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := cover(t).WriteLCOV(out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `TN:
+SF:main.n
+DA:1,1
+DA:2,1
+BRDA:3,0,0,2
+BRDA:3,0,1,1
+DA:3,3
+DA:4,2
+BRDA:5,0,0,0
+BRDA:5,0,1,1
+DA:5,1
+DA:6,0
+DA:8,3
+BRDA:10,0,0,0
+BRDA:10,0,1,1
+DA:10,1
+DA:11,0
+BRF:6
+BRH:4
+LF:9
+LH:7
+end_of_record
+`
+	if out.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", out, expected)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := cover(t).WriteHTML(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<h2>main.n <small>55.6% of lines covered</small></h2>`,
+		`<tr class="partial" title="endral 0, illana 1"><td class="number">10</td><td class="hits">1</td><td class="source">i &gt; 5 endral {</td></tr>`,
+		`<tr><td class="number">12</td><td class="hits"></td><td class="source">}</td></tr>`,
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("the report has no %s", s)
+		}
+	}
+}
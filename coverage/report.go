@@ -0,0 +1,121 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// WriteLCOV writes the coverage in the LCOV tracefile format read by
+// genhtml and most editors. Each if statement is a block of two
+// branches, 0 for endral and 1 for illana.
+func (c *Coverage) WriteLCOV(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	for _, file := range c.files {
+		fmt.Fprintln(w, "TN:")
+		fmt.Fprintf(w, "SF:%s\n", file.Path)
+		found, hit, branchesFound, branchesHit := 0, 0, 0, 0
+		for _, line := range file.Lines() {
+			for block, branch := range line.Branches {
+				for number, taken := range []int64{branch.Then, branch.Else} {
+					branchesFound++
+					switch {
+					case branch.Then+branch.Else == 0:
+						// the condition never ran
+						fmt.Fprintf(w, "BRDA:%d,%d,%d,-\n", line.Number, block, number)
+					default:
+						if taken > 0 {
+							branchesHit++
+						}
+						fmt.Fprintf(w, "BRDA:%d,%d,%d,%d\n", line.Number, block, number, taken)
+					}
+				}
+			}
+			if line.Hits < 0 {
+				continue
+			}
+			found++
+			if line.Hits > 0 {
+				hit++
+			}
+			fmt.Fprintf(w, "DA:%d,%d\n", line.Number, line.Hits)
+		}
+		fmt.Fprintf(w, "BRF:%d\nBRH:%d\n", branchesFound, branchesHit)
+		fmt.Fprintf(w, "LF:%d\nLH:%d\n", found, hit)
+		fmt.Fprintln(w, "end_of_record")
+	}
+	return w.Flush()
+}
+
+// WriteHTML writes the source of the files with each line marked as
+// covered, partly covered or not covered.
+func (c *Coverage) WriteHTML(out io.Writer) error {
+	type row struct {
+		Line
+		Class string
+		Note  string
+	}
+	type page struct {
+		Path    string
+		Percent string
+		Rows    []row
+	}
+	pages := []page{}
+	for _, file := range c.files {
+		rows := []row{}
+		found, covered := 0, 0
+		for _, line := range file.Lines() {
+			r := row{Line: line}
+			if line.Hits >= 0 {
+				found++
+				switch {
+				case line.Covered():
+					covered++
+					r.Class = "covered"
+				case line.Hits > 0:
+					r.Class = "partial"
+				default:
+					r.Class = "uncovered"
+				}
+			}
+			notes := []string{}
+			for _, branch := range line.Branches {
+				notes = append(notes, fmt.Sprintf("endral %d, illana %d", branch.Then, branch.Else))
+			}
+			r.Note = strings.Join(notes, "; ")
+			rows = append(rows, r)
+		}
+		percent := "100.0%"
+		if found > 0 {
+			percent = fmt.Sprintf("%.1f%%", float64(covered)*100/float64(found))
+		}
+		pages = append(pages, page{Path: file.Path, Percent: percent, Rows: rows})
+	}
+	return report.Execute(out, pages)
+}
+
+var report = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Niral coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; white-space: pre; }
+td { padding: 0 0.5em; }
+td.number, td.hits { text-align: right; color: #888; }
+tr.covered td.source { background: #dfd; }
+tr.partial td.source { background: #ffc; }
+tr.uncovered td.source { background: #fdd; }
+</style>
+</head>
+<body>
+{{range .}}<h2>{{.Path}} <small>{{.Percent}} of lines covered</small></h2>
+<table>
+{{range .Rows}}<tr{{with .Class}} class="{{.}}"{{end}}{{if .Note}} title="{{.Note}}"{{end}}><td class="number">{{.Number}}</td><td class="hits">{{if ge .Hits 0}}{{.Hits}}{{end}}</td><td class="source">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,485 @@
+// Package dap implements a Debug Adapter Protocol server that runs a
+// Niral program on the evaluator, spoken over stdio by `niral dap`.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+	"github.com/iam-naveen/compiler/wire"
+)
+
+// The language has no functions yet, the program runs as a single thread
+// with a single frame.
+const (
+	threadID = 1
+	frameID  = 1
+)
+
+type stepMode int
+
+const (
+	run stepMode = iota
+	entry
+	stepIn
+	stepOver
+	stepOut
+)
+
+// Server debugs one program for one client. The program runs on its own
+// goroutine and waits in BeforeStatement while it is stopped.
+type Server struct {
+	evaluator.NopHook // the server only stops before statements
+
+	in      *bufio.Reader
+	out     io.Writer
+	writeMu sync.Mutex // guards out and seq
+	seq     int
+
+	mu          sync.Mutex // guards the fields below
+	path        string
+	program     *tree.Program
+	stopOnEntry bool
+	files       string // the directory of the file builtins, none when empty
+	configured  bool
+	started     bool
+	breakpoints map[string]map[int]bool // lines by absolute path
+	step        stepMode
+	stepDepth   int
+	pause       bool
+	terminate   bool
+	lastLine    int
+	stopped     *stop // nil while the program runs
+
+	resume chan struct{}
+	done   chan struct{} // closed when the program ends
+}
+
+// stop is where the program waits.
+type stop struct {
+	stmt   tree.Stmt
+	depth  int
+	scopes []*object.Environment // innermost first
+}
+
+// errTerminated unwinds the program when the client asks to end it.
+var errTerminated = errors.New("terminated")
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:          bufio.NewReader(in),
+		out:         out,
+		breakpoints: map[string]map[int]bool{},
+		resume:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+}
+
+// Serve answers requests until the client disconnects or closes the
+// input, ending the program if it still runs.
+func (s *Server) Serve() error {
+	defer s.end()
+	for {
+		body, err := wire.Read(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := request{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+		if !s.handle(req) {
+			return nil
+		}
+	}
+}
+
+func (s *Server) send(message any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.seq++
+	switch message := message.(type) {
+	case *response:
+		message.Seq = s.seq
+	case *event:
+		message.Seq = s.seq
+	}
+	if err := wire.Write(s.out, message); err != nil {
+		panic(err)
+	}
+}
+
+func (s *Server) reply(req request, body any) {
+	s.send(&response{Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: body})
+}
+
+func (s *Server) fail(req request, message string) {
+	s.send(&response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Message: message})
+}
+
+func (s *Server) event(name string, body any) {
+	s.send(&event{Type: "event", Event: name, Body: body})
+}
+
+// handle answers the request and reports whether to keep serving.
+func (s *Server) handle(req request) bool {
+	switch req.Command {
+	case "initialize":
+		s.reply(req, capabilities{SupportsConfigurationDoneRequest: true, SupportsTerminateRequest: true})
+		s.event("initialized", nil)
+	case "launch":
+		args := launchArguments{}
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		if err := s.launch(args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.reply(req, nil)
+		s.start()
+	case "setBreakpoints":
+		args := setBreakpointsArguments{}
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.reply(req, map[string]any{"breakpoints": s.setBreakpoints(args)})
+	case "configurationDone":
+		s.mu.Lock()
+		s.configured = true
+		s.mu.Unlock()
+		s.reply(req, nil)
+		s.start()
+	case "threads":
+		s.reply(req, map[string]any{"threads": []Thread{{ID: threadID, Name: "main"}}})
+	case "stackTrace":
+		frames := s.stackTrace()
+		s.reply(req, map[string]any{"stackFrames": frames, "totalFrames": len(frames)})
+	case "scopes":
+		s.reply(req, map[string]any{"scopes": s.scopes()})
+	case "variables":
+		args := variablesArguments{}
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			s.fail(req, err.Error())
+			return true
+		}
+		s.reply(req, map[string]any{"variables": s.variables(args.VariablesReference)})
+	case "continue":
+		s.reply(req, map[string]any{"allThreadsContinued": true})
+		s.continueAs(run)
+	case "next":
+		s.reply(req, nil)
+		s.continueAs(stepOver)
+	case "stepIn":
+		s.reply(req, nil)
+		s.continueAs(stepIn)
+	case "stepOut":
+		s.reply(req, nil)
+		s.continueAs(stepOut)
+	case "pause":
+		s.mu.Lock()
+		s.pause = true
+		s.mu.Unlock()
+		s.reply(req, nil)
+	case "terminate":
+		s.reply(req, nil)
+		s.end()
+	case "disconnect":
+		s.end()
+		s.reply(req, nil)
+		return false
+	default:
+		s.fail(req, "unknown command "+req.Command)
+	}
+	return true
+}
+
+func (s *Server) launch(args launchArguments) error {
+	path, err := filepath.Abs(args.Program)
+	if err != nil {
+		return err
+	}
+	program, err := parseFile(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.program, s.stopOnEntry, s.files = path, program, args.StopOnEntry, args.Files
+	return nil
+}
+
+func parseFile(path string) (*tree.Program, error) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, channel := lexer.CreateLexer(input, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s", path, err)
+	}
+	return program, nil
+}
+
+// start runs the program once it is launched and the client is done
+// setting breakpoints.
+func (s *Server) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.program == nil || !s.configured || s.started {
+		return
+	}
+	s.started = true
+	if s.stopOnEntry {
+		s.step = entry
+	}
+	go s.run(s.program)
+}
+
+func (s *Server) run(program *tree.Program) {
+	defer close(s.done)
+	interpreter := &evaluator.Interpreter{
+		In:    strings.NewReader(""),
+		Out:   &output{server: s, category: "stdout"},
+		Hook:  s,
+		Path:  s.path,
+		Files: s.files,
+	}
+	err := s.runProgram(interpreter, program)
+	if err == errTerminated {
+		s.event("terminated", nil)
+		return
+	}
+	code := 0
+	if err != nil {
+		s.event("output", outputEvent{Category: "stderr", Output: err.Error() + "\n"})
+		code = 1
+	}
+	s.event("exited", exitedEvent{ExitCode: code})
+	s.event("terminated", nil)
+}
+
+func (s *Server) runProgram(interpreter *evaluator.Interpreter, program *tree.Program) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errTerminated {
+				panic(r)
+			}
+			err = errTerminated
+		}
+	}()
+	return interpreter.Run(program, object.NewEnvironment())
+}
+
+// end stops the program if it runs and waits for it to unwind.
+func (s *Server) end() {
+	s.mu.Lock()
+	started := s.started
+	s.terminate = true
+	if s.stopped != nil {
+		s.stopped = nil
+		s.resume <- struct{}{}
+	}
+	s.mu.Unlock()
+	if started {
+		<-s.done
+	}
+}
+
+// BeforeStatement stops the program when a breakpoint, a step or a pause
+// asks for it, and waits until the client lets it go on.
+func (s *Server) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	s.mu.Lock()
+	if s.terminate {
+		s.mu.Unlock()
+		panic(errTerminated)
+	}
+	line := tree.SpanOf(stmt).Start.Line
+	reason := s.reason(line, depth)
+	s.lastLine = line
+	if reason == "" {
+		s.mu.Unlock()
+		return
+	}
+	scopes := []*object.Environment{}
+	for scope := env; scope != nil; scope = scope.Outer() {
+		scopes = append(scopes, scope)
+	}
+	s.stopped = &stop{stmt: stmt, depth: depth, scopes: scopes}
+	s.step, s.pause = run, false
+	s.mu.Unlock()
+
+	s.event("stopped", stoppedEvent{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+	<-s.resume
+
+	s.mu.Lock()
+	terminate := s.terminate
+	s.mu.Unlock()
+	if terminate {
+		panic(errTerminated)
+	}
+}
+
+func (s *Server) reason(line, depth int) string {
+	switch {
+	case s.step == entry:
+		return "entry"
+	case s.step == stepIn,
+		s.step == stepOver && depth <= s.stepDepth,
+		s.step == stepOut && depth < s.stepDepth:
+		return "step"
+	case s.pause:
+		return "pause"
+	case line != s.lastLine && s.breakpoints[s.path][line]:
+		// a line with many statements stops once
+		return "breakpoint"
+	}
+	return ""
+}
+
+func (s *Server) continueAs(mode stepMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped == nil {
+		return
+	}
+	s.step, s.stepDepth = mode, s.stopped.depth
+	s.stopped = nil
+	s.resume <- struct{}{}
+}
+
+// setBreakpoints keeps the lines that have a statement starting on them.
+func (s *Server) setBreakpoints(args setBreakpointsArguments) []Breakpoint {
+	path, _ := filepath.Abs(args.Source.Path)
+	s.mu.Lock()
+	program := s.program
+	if path != s.path {
+		program = nil
+	}
+	s.mu.Unlock()
+	if program == nil {
+		program, _ = parseFile(path)
+	}
+	lines := statementLines(program)
+
+	set := map[int]bool{}
+	breakpoints := []Breakpoint{}
+	for _, requested := range args.Breakpoints {
+		breakpoint := Breakpoint{Line: requested.Line, Verified: lines[requested.Line]}
+		if breakpoint.Verified {
+			set[requested.Line] = true
+		} else {
+			breakpoint.Message = "No statement starts on this line"
+		}
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	s.mu.Lock()
+	s.breakpoints[path] = set
+	s.mu.Unlock()
+	return breakpoints
+}
+
+func statementLines(program *tree.Program) map[int]bool {
+	lines := map[int]bool{}
+	if program == nil {
+		return lines
+	}
+	tree.Inspect(program, func(node tree.Node) bool {
+		switch node.(type) {
+		case nil:
+			return false
+		case *tree.Program, *tree.Block:
+		case tree.Stmt:
+			lines[tree.SpanOf(node).Start.Line] = true
+		}
+		return true
+	})
+	return lines
+}
+
+func (s *Server) stackTrace() []StackFrame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped == nil {
+		return []StackFrame{}
+	}
+	start := tree.SpanOf(s.stopped.stmt).Start
+	return []StackFrame{{
+		ID:     frameID,
+		Name:   "main",
+		Source: Source{Name: filepath.Base(s.path), Path: s.path},
+		Line:   start.Line,
+		Column: start.Column,
+	}}
+}
+
+// scopes lists the chain of environments around the stopped statement,
+// a variables reference is the position of the scope in the chain plus
+// one.
+func (s *Server) scopes() []Scope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scopes := []Scope{}
+	if s.stopped == nil {
+		return scopes
+	}
+	for i := range s.stopped.scopes {
+		name := "Locals"
+		if i == len(s.stopped.scopes)-1 {
+			name = "Globals"
+		} else if i > 0 {
+			name = "Enclosing " + strconv.Itoa(i)
+		}
+		scopes = append(scopes, Scope{Name: name, VariablesReference: i + 1})
+	}
+	return scopes
+}
+
+func (s *Server) variables(reference int) []Variable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	variables := []Variable{}
+	if s.stopped == nil || reference < 1 || reference > len(s.stopped.scopes) {
+		return variables
+	}
+	env := s.stopped.scopes[reference-1]
+	for _, name := range env.Names() {
+		value, _ := env.Get(name)
+		text := value.Inspect()
+		if value.Type() == object.STRING_OBJ {
+			text = strconv.Quote(text)
+		}
+		variables = append(variables, Variable{Name: name, Value: text, Type: string(value.Type())})
+	}
+	return variables
+}
+
+// output sends what the program writes to the client.
+type output struct {
+	server   *Server
+	category string
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.server.event("output", outputEvent{Category: o.category, Output: string(p)})
+	return len(p), nil
+}
@@ -0,0 +1,241 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iam-naveen/compiler/wire"
+)
+
+// client talks to a server running on its own goroutine.
+type client struct {
+	t        *testing.T
+	in       *io.PipeWriter
+	messages chan map[string]any
+	seq      int
+	path     string
+}
+
+func newClient(t *testing.T, program string) *client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.n")
+	if err := os.WriteFile(path, []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	c := &client{t: t, in: inWriter, messages: make(chan map[string]any, 100), path: path}
+	go func() {
+		NewServer(inReader, outWriter).Serve()
+		outWriter.Close()
+	}()
+	go func() {
+		reader := bufio.NewReader(outReader)
+		for {
+			body, err := wire.Read(reader)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			message := map[string]any{}
+			json.Unmarshal(body, &message)
+			c.messages <- message
+		}
+	}()
+	// closing the input ends the session like a disconnect
+	t.Cleanup(func() { inWriter.Close() })
+	return c
+}
+
+func (c *client) send(command string, arguments any) {
+	c.seq++
+	wire.Write(c.in, map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+}
+
+// expect skips messages until the event or the response to the command,
+// named by kind, arrives.
+func (c *client) expect(kind string) map[string]any {
+	c.t.Helper()
+	for {
+		select {
+		case message, ok := <-c.messages:
+			if !ok {
+				c.t.Fatalf("the server stopped before %s", kind)
+			}
+			if message["event"] == kind || message["command"] == kind {
+				if message["success"] == false {
+					c.t.Fatalf("%s failed: %v", kind, message["message"])
+				}
+				body, _ := message["body"].(map[string]any)
+				return body
+			}
+		case <-time.After(5 * time.Second):
+			c.t.Fatalf("timed out waiting for %s", kind)
+		}
+	}
+}
+
+func (c *client) request(command string, arguments any) map[string]any {
+	c.t.Helper()
+	c.send(command, arguments)
+	return c.expect(command)
+}
+
+// launch starts the program with breakpoints on the lines.
+func (c *client) launch(stopOnEntry bool, lines ...int) []any {
+	c.t.Helper()
+	c.request("initialize", map[string]any{"adapterID": "niral"})
+	c.expect("initialized")
+	c.request("launch", map[string]any{"program": c.path, "stopOnEntry": stopOnEntry})
+	breakpoints := []map[string]any{}
+	for _, line := range lines {
+		breakpoints = append(breakpoints, map[string]any{"line": line})
+	}
+	body := c.request("setBreakpoints", map[string]any{"source": map[string]any{"path": c.path}, "breakpoints": breakpoints})
+	c.request("configurationDone", nil)
+	return body["breakpoints"].([]any)
+}
+
+// stopped waits for the program to stop and returns why and on which line.
+func (c *client) stopped() string {
+	c.t.Helper()
+	reason := c.expect("stopped")["reason"]
+	frames := c.request("stackTrace", map[string]any{"threadId": threadID})["stackFrames"].([]any)
+	return fmt.Sprintf("%s %v", reason, frames[0].(map[string]any)["line"])
+}
+
+func (c *client) variables() string {
+	c.t.Helper()
+	scopes := c.request("scopes", map[string]any{"frameId": frameID})["scopes"].([]any)
+	out := []string{}
+	for _, scope := range scopes {
+		scope := scope.(map[string]any)
+		variables := c.request("variables", map[string]any{"variablesReference": scope["variablesReference"]})["variables"].([]any)
+		for _, variable := range variables {
+			variable := variable.(map[string]any)
+			out = append(out, fmt.Sprintf("%s.%s=%s:%s", scope["name"], variable["name"], variable["value"], variable["type"]))
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestBreakpointsAndVariables(t *testing.T) {
+	c := newClient(t, "yen a = 1;\nsol s = \"x\";\n\na = a + 2;\na sollu;\n")
+	breakpoints := c.launch(false, 3, 4)
+	if verified := fmt.Sprint(breakpoints[0].(map[string]any)["verified"], breakpoints[1].(map[string]any)["verified"]); verified != "false true" {
+		t.Errorf("verified %s", verified)
+	}
+	if got := c.stopped(); got != "breakpoint 4" {
+		t.Errorf("stopped %s", got)
+	}
+	if got := c.variables(); got != `Globals.a=1:INTEGER Globals.s="x":STRING` {
+		t.Errorf("variables %s", got)
+	}
+	c.send("next", map[string]any{"threadId": threadID})
+	if got := c.stopped(); got != "step 5" {
+		t.Errorf("stopped %s", got)
+	}
+	if got := c.variables(); got != `Globals.a=3:INTEGER Globals.s="x":STRING` {
+		t.Errorf("variables %s", got)
+	}
+	c.send("continue", map[string]any{"threadId": threadID})
+	if output := c.expect("output")["output"]; output != "3\n" {
+		t.Errorf("output %q", output)
+	}
+	if code := c.expect("exited")["exitCode"]; code != float64(0) {
+		t.Errorf("exit code %v", code)
+	}
+}
+
+const loop = `yen i = 0;
+i < 2 varaikkum {
+    i = i + 1;
+    i sollu;
+}
+"done" sollu;
+`
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+		stops []string
+	}{
+		{"over", []string{"next", "next"}, []string{"entry 1", "step 2", "step 6"}},
+		{"in", []string{"next", "stepIn", "stepIn", "stepIn"}, []string{"entry 1", "step 2", "step 3", "step 4", "step 3"}},
+		{"out", []string{"next", "stepIn", "stepOut"}, []string{"entry 1", "step 2", "step 3", "step 6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t, loop)
+			c.launch(true)
+			stops := []string{c.stopped()}
+			for _, step := range tt.steps {
+				c.send(step, map[string]any{"threadId": threadID})
+				stops = append(stops, c.stopped())
+			}
+			if strings.Join(stops, ", ") != strings.Join(tt.stops, ", ") {
+				t.Errorf("stopped at %v, want %v", stops, tt.stops)
+			}
+		})
+	}
+}
+
+func TestBreakpointInLoop(t *testing.T) {
+	c := newClient(t, loop)
+	c.launch(false, 4)
+	for i := 1; i <= 2; i++ {
+		if got := c.stopped(); got != "breakpoint 4" {
+			t.Fatalf("stopped %s", got)
+		}
+		if got := c.variables(); got != fmt.Sprintf("Globals.i=%d:INTEGER", i) {
+			t.Errorf("variables %s", got)
+		}
+		c.send("continue", map[string]any{"threadId": threadID})
+	}
+	c.expect("exited")
+}
+
+func TestPause(t *testing.T) {
+	c := newClient(t, "yen i = 0;\naam varaikkum {\n    i = i + 1;\n}\n")
+	c.launch(false)
+	c.send("pause", map[string]any{"threadId": threadID})
+	// the program may not have reached the loop yet
+	if got := c.stopped(); !strings.HasPrefix(got, "pause ") {
+		t.Errorf("stopped %s", got)
+	}
+	c.request("terminate", nil)
+	c.expect("terminated")
+}
+
+func TestRuntimeError(t *testing.T) {
+	c := newClient(t, "yen a = \"x\";\n")
+	c.launch(false)
+	if output := c.expect("output"); output["category"] != "stderr" || output["output"] != "ERROR: Cannot Assign STRING to INTEGER variable\n" {
+		t.Errorf("output %v", output)
+	}
+	if code := c.expect("exited")["exitCode"]; code != float64(1) {
+		t.Errorf("exit code %v", code)
+	}
+}
+
+func TestLaunchSyntaxError(t *testing.T) {
+	c := newClient(t, "yen a = ;\n")
+	c.request("initialize", nil)
+	c.send("launch", map[string]any{"program": c.path})
+	for message := range c.messages {
+		if message["command"] == "launch" {
+			if message["success"] != false || !strings.HasSuffix(message["message"].(string), "1:9: No prefix handler for ;") {
+				t.Errorf("launch %v", message)
+			}
+			return
+		}
+	}
+}
@@ -2,38 +2,156 @@ package evaluator
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
 )
 
+// Builtin is a function of the language written in Go. Params are the
+// types of the arguments, an empty type accepts any value and ERROR also
+// accepts the error an argument failed with. Result is the type of the
+// value returned, empty when it depends on the arguments. Only a builtin
+// marked Big is given integers too large for 64 bits.
+type Builtin struct {
+	Params []object.ObjectType
+	Result object.ObjectType
+	Big    bool
+	Fn     func(i *Interpreter, args []object.Object) object.Object
+}
 
-var Builtins = map[string]func(args ...interface{}) interface{}{
-	// print function
-	"sollu": func(args ...interface{}) interface{} {
-		for _, arg := range args {
-			fmt.Println(arg)
-		}
-		return nil
+// Builtins are the functions programs can call by name.
+var Builtins = map[string]*Builtin{
+	// assertions, a failed assertion stops the program
+	"assert_equal": {
+		Params: []object.ObjectType{"", ""},
+		Result: object.NULL_OBJ,
+		Fn:     assertEqual,
 	},
-	// input function
-	"kodu": func(args ...interface{}) interface{} {
-		if len(args) > 1 {
-			fmt.Println("kodu function takes only one argument")
-			return nil
-		}
-		if args[0] != nil {
-			switch t := args[0].(type) {
-			case *object.String:
-				fmt.Scanln(&t.Value)
-				return t
-			case *object.Integer:
-				fmt.Scanln(&t.Value)
-				return t
-			default:
-				fmt.Println("kodu function takes only one argument of type INTEGER or STRING")
-				return nil
-			}
-		} 	
-		return nil
+	"assert_true": {
+		Params: []object.ObjectType{object.BOOLEAN_OBJ},
+		Result: object.NULL_OBJ,
+		Fn:     assertTrue,
+	},
+	"assert_error": {
+		Params: []object.ObjectType{object.ERROR_OBJ, object.STRING_OBJ},
+		Result: object.NULL_OBJ,
+		Fn:     assertError,
 	},
+
+	// strings
+	"split":      {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ}, Result: object.ARRAY_OBJ, Fn: split},
+	"join":       {Params: []object.ObjectType{object.ARRAY_OBJ, object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: join},
+	"trim":       {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: trim},
+	"upper":      {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: upper},
+	"lower":      {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: lower},
+	"contains":   {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ}, Result: object.BOOLEAN_OBJ, Fn: contains},
+	"index_of":   {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ}, Result: object.INTEGER_OBJ, Fn: indexOf},
+	"replace":    {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: replace},
+	"repeat":     {Params: []object.ObjectType{object.STRING_OBJ, object.INTEGER_OBJ}, Result: object.STRING_OBJ, Fn: repeat},
+	"substring":  {Params: []object.ObjectType{object.STRING_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ}, Result: object.STRING_OBJ, Fn: substring},
+	"parse_int":  {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.INTEGER_OBJ, Fn: parseInt},
+	"format_int": {Params: []object.ObjectType{object.INTEGER_OBJ}, Result: object.STRING_OBJ, Fn: formatInt},
+	"rune_count": {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.INTEGER_OBJ, Fn: runeCount},
+	"byte_count": {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.INTEGER_OBJ, Fn: byteCount},
+	"format":     {Params: []object.ObjectType{object.STRING_OBJ, object.ARRAY_OBJ}, Result: object.STRING_OBJ, Fn: format},
+
+	// math, taking Integers and Floats
+	"abs":   {Params: []object.ObjectType{""}, Fn: abs},
+	"min":   {Params: []object.ObjectType{"", ""}, Fn: minimum},
+	"max":   {Params: []object.ObjectType{"", ""}, Fn: maximum},
+	"pow":   {Params: []object.ObjectType{"", ""}, Fn: pow},
+	"sqrt":  {Params: []object.ObjectType{""}, Result: object.FLOAT_OBJ, Fn: sqrt},
+	"floor": {Params: []object.ObjectType{""}, Result: object.INTEGER_OBJ, Fn: floor},
+	"ceil":  {Params: []object.ObjectType{""}, Result: object.INTEGER_OBJ, Fn: ceil},
+	"gcd":   {Params: []object.ObjectType{object.INTEGER_OBJ, object.INTEGER_OBJ}, Result: object.INTEGER_OBJ, Big: true, Fn: gcd},
+	"range": {Params: []object.ObjectType{object.INTEGER_OBJ, object.INTEGER_OBJ}, Result: object.RANGE_OBJ, Fn: integerRange},
+
+	// random, from the generator of the interpreter
+	"random_int":    {Params: []object.ObjectType{object.INTEGER_OBJ, object.INTEGER_OBJ}, Result: object.INTEGER_OBJ, Fn: randomInt},
+	"random_choice": {Params: []object.ObjectType{object.ARRAY_OBJ}, Fn: randomChoice},
+	"shuffle":       {Params: []object.ObjectType{object.ARRAY_OBJ}, Result: object.ARRAY_OBJ, Fn: shuffle},
+
+	// files, under the Files directory of the interpreter
+	"read_file":   {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.STRING_OBJ, Fn: readFile},
+	"read_lines":  {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.ARRAY_OBJ, Fn: readLines},
+	"write_file":  {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ}, Result: object.NULL_OBJ, Fn: writeFile},
+	"append_file": {Params: []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ}, Result: object.NULL_OBJ, Fn: appendFile},
+	"file_exists": {Params: []object.ObjectType{object.STRING_OBJ}, Result: object.BOOLEAN_OBJ, Fn: fileExists},
+
+	// json
+	"json_decode": {Params: []object.ObjectType{object.STRING_OBJ}, Fn: jsonDecode},
+	"json_encode": {Params: []object.ObjectType{""}, Result: object.STRING_OBJ, Fn: jsonEncode},
+
+	// null, the value of an input after the end and of a json null
+	"is_null": {Params: []object.ObjectType{""}, Result: object.BOOLEAN_OBJ, Fn: isNull},
+}
+
+var null = &object.Null{}
+
+func (i *Interpreter) evalCall(call *tree.Call, env *object.Environment) object.Object {
+	name := call.Function.Name
+	builtin, ok := Builtins[name]
+	if !ok {
+		return &object.Error{Message: "Unknown function " + name}
+	}
+	if len(call.Args) != len(builtin.Params) {
+		return &object.Error{Message: fmt.Sprintf("%s takes %d arguments, got %d", name, len(builtin.Params), len(call.Args))}
+	}
+	args := make([]object.Object, len(call.Args))
+	for n, arg := range call.Args {
+		value := i.evaluateExpression(arg, env)
+		want := builtin.Params[n]
+		if err, ok := value.(*object.Error); ok && want != object.ERROR_OBJ {
+			return err
+		}
+		if want != "" && want != object.ERROR_OBJ && value.Type() != want {
+			return &object.Error{Message: fmt.Sprintf("Argument %d of %s must be %s, got %s", n+1, name, want, value.Type())}
+		}
+		if _, ok := value.(*object.BigInteger); ok && want == object.INTEGER_OBJ && !builtin.Big {
+			return &object.Error{Message: fmt.Sprintf("Argument %d of %s is too large", n+1, name)}
+		}
+		args[n] = value
+	}
+	i.call(name, args...)
+	return builtin.Fn(i, args)
+}
+
+func isNull(i *Interpreter, args []object.Object) object.Object {
+	return &object.Boolean{Value: args[0].Type() == object.NULL_OBJ}
+}
+
+// show writes a value the way it is written in a program.
+func show(value object.Object) string {
+	if value.Type() == object.STRING_OBJ {
+		return strconv.Quote(value.Inspect())
+	}
+	return value.Inspect()
+}
+
+func assertEqual(i *Interpreter, args []object.Object) object.Object {
+	got, want := args[0], args[1]
+	if got.Type() != want.Type() || got.Inspect() != want.Inspect() {
+		return &object.Error{Message: fmt.Sprintf("assert_equal: got %s, want %s", show(got), show(want))}
+	}
+	return null
+}
+
+func assertTrue(i *Interpreter, args []object.Object) object.Object {
+	if !args[0].(*object.Boolean).Value {
+		return &object.Error{Message: "assert_true: the condition is false"}
+	}
+	return null
+}
+
+func assertError(i *Interpreter, args []object.Object) object.Object {
+	err, ok := args[0].(*object.Error)
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("assert_error: got %s, want an error", show(args[0]))}
+	}
+	if want := args[1].(*object.String).Value; !strings.Contains(err.Message, want) {
+		return &object.Error{Message: fmt.Sprintf("assert_error: got %q, want an error containing %q", err.Message, want)}
+	}
+	return null
 }
@@ -0,0 +1,312 @@
+package evaluator
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`assert_equal(1 + 2, 3); assert_true(1 < 2); assert_error("x" - 1, "Type Mismatch");`, ""},
+		{`assert_equal(1 + 2, 4);`, "1:1: assert_equal: got 3, want 4"},
+		{"yen a = 1;\nassert_equal(a, \"1\");", `2:1: assert_equal: got 1, want "1"`},
+		{`assert_true(1 > 2);`, "1:1: assert_true: the condition is false"},
+		{`assert_error(1, "");`, "1:1: assert_error: got 1, want an error"},
+		{`assert_error("x" - 1, "Unknown");`, `1:1: assert_error: got "Type Mismatch: Cannot perform operation with STRING and INTEGER", want an error containing "Unknown"`},
+		{`assert_true(1);`, "1:1: Argument 1 of assert_true must be BOOLEAN, got INTEGER"},
+		{`assert_true();`, "1:1: assert_true takes 1 arguments, got 0"},
+		{`assert_true("x" - 1);`, "1:1: Type Mismatch: Cannot perform operation with STRING and INTEGER"},
+		{`check(1);`, "1:1: Unknown function check"},
+	}
+	for _, tt := range tests {
+		interpreter := &Interpreter{Out: &bytes.Buffer{}}
+		err := interpreter.Run(parse(tt.input), object.NewEnvironment())
+		got := ""
+		if err != nil {
+			runtime := err.(*RuntimeError)
+			got = runtime.Pos.String() + ": " + runtime.Message
+		}
+		if got != tt.err {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.input, got, tt.err)
+		}
+	}
+}
+
+// evalPrint prints the value of expr and returns what was printed, an
+// error value is printed like any other.
+func evalPrint(expr string) string {
+	out := &bytes.Buffer{}
+	interpreter := &Interpreter{Out: out}
+	if err := interpreter.Run(parse(expr+" sollu;"), object.NewEnvironment()); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`split("a,b,,c", ",")`, `["a", "b", "", "c"]`},
+		{`split("தமிழ்", "")`, `["த", "மி", "ழ்"]`},
+		{`split("a,b", ",")[1]`, "b"},
+		{`join(split("a b c", " "), "-")`, "a-b-c"},
+		{`join([1, "x", aam], ", ")`, "1, x, true"},
+		{`trim("  vanakkam ")`, "vanakkam"},
+		{`upper("Nila") + lower("NILA")`, "NILAnila"},
+		{`contains("vanakkam", "kka")`, "true"},
+		{`contains("vanakkam", "x")`, "false"},
+		{`index_of("தமிழ் நாடு", "நா")`, "4"},
+		{`index_of("abc", "x")`, "-1"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", -1)`, "ERROR: repeat: count -1 is negative"},
+		{`repeat("ab", 1000000000)`, "ERROR: repeat: the result of 1000000000 copies is too long"},
+		{`substring("vanakkam", 2, 5)`, "nak"},
+		{`substring("தமிழ்", 0, 2)`, "தமி"},
+		{`"தமிழ்" neelam`, "3"},
+		{`"தமிழ்"[2]`, "ழ்"},
+		{`rune_count("தமிழ்")`, "5"},
+		{`byte_count("தமிழ்")`, "15"},
+		{`substring("abc", 2, 4)`, "ERROR: substring: 2 to 4 is out of range for 3 letters"},
+		{`parse_int(" 42 ") + 1`, "43"},
+		{`parse_int("4x2")`, `ERROR: parse_int: "4x2" is not an integer`},
+		{`parse_int("99999999999999999999")`, `ERROR: parse_int: "99999999999999999999" is out of range`},
+		{`format_int(7) + "7"`, "77"},
+		{`split("a", 1)`, "ERROR: Argument 2 of split must be STRING, got INTEGER"},
+		{`[1, 2] neelam`, "2"},
+		{`[1, 2][2]`, "ERROR: Index out of range"},
+	}
+	for _, tt := range tests {
+		if got := evalPrint(tt.expr); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`format("{} is {}", ["nila", 7])`, "nila is 7"},
+		{`format("[{:6}][{:6}]", ["ab", 42])`, "[ab    ][    42]"},
+		{`format("[{:>4}][{:<4}][{:^5}]", ["ab", 1, "x"])`, "[  ab][1   ][  x  ]"},
+		{`format("[{:*^6}][{:.>4}]", ["ab", 7])`, "[**ab**][...7]"},
+		{`format("[{:5}]", ["தமிழ்"])`, "[தமிழ்  ]"},
+		{`format("[{:வ<4}]", [1])`, "[1வவவ]"},
+		{`format("{:05}|{:04}|{:03}", [42, 0 - 7, 1.5])`, "00042|-007|1.5"},
+		{`format("{:b} {:o} {:x} {:X} {:d}", [5, 8, 255, 255, 9])`, "101 10 ff FF 9"},
+		{`format("{:08b}", [5])`, "00000101"},
+		{`format("{:x}", [0 - 255])`, "-ff"},
+		{`format("{{{}}}", [1])`, "{1}"},
+		{`format("{:3}", [[1]])`, "[1]"},
+		{`format("", [])`, ""},
+		{`format("{} {}", [1])`, "ERROR: format: placeholder 2 has no value, the array has 1"},
+		{`format("{}", [1, 2])`, "ERROR: format: 2 values for 1 placeholders"},
+		{`format("{:q}", [1])`, "ERROR: format: {:q} is not a placeholder"},
+		{`format("{5}", [1])`, "ERROR: format: {5} is not a placeholder, the spec starts with :"},
+		{`format("{:x}", ["a"])`, "ERROR: format: {:x} needs an Integer, got STRING"},
+		{`format("{:05}", ["a"])`, "ERROR: format: {:05} pads numbers with zeros, got STRING"},
+		{`format("{:99999}", [1])`, "ERROR: format: the width of {:99999} is above 65536"},
+		{`format("a {", [])`, "ERROR: format: the placeholder { is not closed"},
+		{`format("a }", [])`, "ERROR: format: a } is not closing a placeholder, write }} for a brace"},
+	}
+	for _, tt := range tests {
+		if got := evalPrint(tt.expr); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`abs(-3)`, "3"},
+		{`abs(-2.5)`, "2.5"},
+		{`abs(-9223372036854775807 - 1)`, "ERROR: abs: Integer overflow"},
+		{`min(2, 3) + max(2, 3)`, "5"},
+		{`min(2, 1.5)`, "1.5"},
+		{`max(2, 2.0)`, "2"},
+		{`max("a", 1)`, "ERROR: max: want a number, got STRING"},
+		{`pow(2, 10)`, "1024"},
+		{`pow(-1, 3)`, "-1"},
+		{`pow(2, 0.5)`, "1.4142135623730951"},
+		{`pow(2.0, -1)`, "0.5"},
+		{`pow(2, -1)`, "ERROR: pow: exponent -1 is negative, use a float base for fractions"},
+		{`pow(2, 63)`, "ERROR: pow: Integer overflow"},
+		{`pow(10, 9999999)`, "ERROR: pow: the result of 10 to the power 9999999 is too large"},
+		{`sqrt(16)`, "4.0"},
+		{`sqrt(-1)`, "ERROR: sqrt: -1 is negative"},
+		{`floor(2.7) + ceil(2.2)`, "5"},
+		{`floor(-2.5)`, "-3"},
+		{`ceil(4)`, "4"},
+		{`floor(sqrt(99.0))`, "9"},
+		{`floor(100000000000000000000.0)`, "ERROR: floor: Integer overflow"},
+		{`gcd(12, -18)`, "6"},
+		{`gcd(0, 0)`, "0"},
+		{`gcd(1.5, 3)`, "ERROR: Argument 1 of gcd must be INTEGER, got FLOAT"},
+	}
+	for _, tt := range tests {
+		if got := evalPrint(tt.expr); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	run := func(seed int64, input string) string {
+		out := &bytes.Buffer{}
+		interpreter := &Interpreter{Out: out, Rand: rand.New(rand.NewSource(seed))}
+		if err := interpreter.Run(parse(input), object.NewEnvironment()); err != nil {
+			return err.Error()
+		}
+		return strings.TrimSuffix(out.String(), "\n")
+	}
+	program := `random_int(1, 100) sollu; random_choice(["a", "b", "c"]) sollu; shuffle([1, 2, 3, 4, 5]) sollu;`
+	if first, second := run(3, program), run(3, program); first != second {
+		t.Errorf("the same seed printed\n%s\nand\n%s", first, second)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`yen a = random_int(5, 5); a sollu;`, "5"},
+		{`random_int(-9223372036854775807 - 1, 9223372036854775807) > 0 || aam sollu;`, "true"},
+		{`varisai a = [1, 2, 3]; varisai b = shuffle(a); b[0] + b[1] + b[2] sollu; a sollu;`, "6\n[1, 2, 3]"},
+		{`random_choice([7]) sollu;`, "7"},
+		{`random_int(2, 1) sollu;`, "ERROR: random_int: low 2 is above high 1"},
+		{`random_choice([]) sollu;`, "ERROR: random_choice: the array is empty"},
+	}
+	for _, tt := range tests {
+		if got := run(1, tt.input); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.input, got, tt.expected)
+		}
+	}
+	for n := 0; n < 100; n++ {
+		value := run(int64(n), `random_int(1, 6) sollu;`)
+		if value < "1" || value > "6" || len(value) != 1 {
+			t.Fatalf("seed %d: random_int(1, 6) gave %s", n, value)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := modules(t, map[string]string{
+		"root/notes.txt": "first\r\nsecond\n",
+		"root/empty.txt": "",
+		"root/sub/a.txt": "a",
+		"secret.txt":     "hidden",
+		"root/sub/b.txt": "b\n\n",
+	})
+	root := filepath.Join(dir, "root")
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`read_file("notes.txt") sollu;`, "first\r\nsecond\n"},
+		{`read_lines("notes.txt") sollu;`, `["first", "second"]`},
+		{`read_lines("empty.txt") sollu; read_lines("sub/b.txt") sollu;`, "[]\n[\"b\", \"\"]"},
+		{`write_file("out.txt", "a"); append_file("out.txt", "b"); append_file("new.txt", "c"); read_file("out.txt") + read_file("new.txt") sollu;`, "abc"},
+		{`write_file("out.txt", "z"); read_file("out.txt") sollu;`, "z"},
+		{`file_exists("sub/a.txt") sollu; file_exists("missing.txt") sollu;`, "true\nfalse"},
+		{`read_file("missing.txt") sollu;`, "ERROR: read_file: missing.txt: no such file or directory"},
+		{`write_file("missing/x.txt", "") sollu;`, "ERROR: write_file: missing/x.txt: no such file or directory"},
+		{`read_file("../secret.txt") sollu;`, "ERROR: read_file: permission denied, ../secret.txt is outside of ROOT"},
+		{`read_file("sub/../../secret.txt") sollu;`, "ERROR: read_file: permission denied, sub/../../secret.txt is outside of ROOT"},
+		{`read_file("link.txt") sollu;`, "ERROR: read_file: permission denied, link.txt is outside of ROOT"},
+		{`file_exists("DIR/secret.txt") sollu;`, "ERROR: file_exists: permission denied, DIR/secret.txt is outside of ROOT"},
+		{`read_file("DIR/root/sub/a.txt") sollu;`, "a"},
+		{`read_file(1) sollu;`, "ERROR: Argument 1 of read_file must be STRING, got INTEGER"},
+		// an error that is not printed stops the program
+		{`append_file("../x.txt", "");`, "ERROR: append_file: permission denied, ../x.txt is outside of ROOT"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		interpreter := &Interpreter{Out: out, Files: root}
+		input := strings.ReplaceAll(tt.input, "DIR", filepath.ToSlash(dir))
+		got := ""
+		if err := interpreter.Run(parse(input), object.NewEnvironment()); err != nil {
+			got = err.Error()
+		} else {
+			got = strings.TrimSuffix(out.String(), "\n")
+		}
+		got = strings.ReplaceAll(strings.ReplaceAll(got, root, "ROOT"), filepath.ToSlash(dir), "DIR")
+		if got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.input, got, tt.expected)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.txt")); err == nil {
+		t.Error("append_file wrote outside of the root")
+	}
+
+	// the zero value of an interpreter allows no file
+	if got := evalPrint(`file_exists("notes.txt")`); got != "ERROR: file_exists: permission denied, file access is turned off" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		data     string // the text of the variable data
+		expr     string
+		expected string
+	}{
+		{`{"b": [1, 2.5, 1e2, true, null], "a": "x"}`, `json_decode(data)`, `{"a": "x", "b": [1, 2.5, 100.0, true, null]}`},
+		{`{"name": "nila"}`, `json_decode(data)["name"]`, "nila"},
+		{`{"name": "nila"}`, `json_decode(data)["age"]`, `ERROR: Key "age" not found`},
+		{`{"name": "nila"}`, `json_decode(data)[0]`, "ERROR: Key must be a String"},
+		{`{"a": 1, "b": 2}`, `json_decode(data) neelam`, "2"},
+		{` "\u0ba4மிழ்" `, `json_decode(data)`, "தமிழ்"},
+		{`12345678901234567890`, `json_decode(data)`, "12345678901234567000.0"},
+		{`[1e400]`, `json_decode(data)`, "ERROR: json_decode: 1e400 is out of range at offset 1"},
+		{`{"a": [1, -1e400]}`, `json_decode(data)`, "ERROR: json_decode: -1e400 is out of range at offset 10"},
+		{`"1e400"`, `json_decode(data)`, "1e400"},
+		{`1e-400`, `json_decode(data)`, "0.0"},
+		{`{"a": 1,}`, `json_decode(data)`, "ERROR: json_decode: invalid character '}' looking for beginning of object key string at offset 9"},
+		{`[1, 2`, `json_decode(data)`, "ERROR: json_decode: unexpected end of JSON input at offset 5"},
+		{``, `json_decode(data)`, "ERROR: json_decode: unexpected end of JSON input at offset 0"},
+		{`1 2`, `json_decode(data)`, "ERROR: json_decode: unexpected text after the value at offset 3"},
+		{`{"z": {"b": 1, "a": ["<&>\n"]}, "a": null}`, `json_encode(json_decode(data))`, `{"a":null,"z":{"a":["<&>\n"],"b":1}}`},
+		{``, `json_encode([1, 2.0, "x", aam])`, `[1,2.0,"x",true]`},
+		{``, `json_encode(1 / 0)`, "ERROR: Division by zero"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		env := object.NewEnvironment()
+		env.Set("data", &object.String{Value: tt.data})
+		interpreter := &Interpreter{Out: out}
+		if err := interpreter.Run(parse(tt.expr+" sollu;"), env); err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := strings.TrimSuffix(out.String(), "\n"); got != tt.expected {
+			t.Errorf("%s on %s\ngot  %s\nwant %s", tt.expr, tt.data, got, tt.expected)
+		}
+	}
+
+	// whole numbers too large for an Integer are kept in big integer mode
+	out := &bytes.Buffer{}
+	env := object.NewEnvironment()
+	env.Set("data", &object.String{Value: `[12345678901234567890]`})
+	interpreter := &Interpreter{Out: out, BigIntegers: true}
+	if err := interpreter.Run(parse(`json_encode(json_decode(data)) sollu;`), env); err != nil || out.String() != "[12345678901234567890]\n" {
+		t.Errorf("got %q, %v", out.String(), err)
+	}
+}
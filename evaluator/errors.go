@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// evalTryStatement runs the muyarchi block, and the pidi block with the
+// error when one stopped it. The iruthiyaga block runs last, also when
+// the pidi block fails or a niruthu or thodar leaves the statement.
+func (i *Interpreter) evalTryStatement(stmt *tree.TryStmt, env *object.Environment) {
+	if stmt.Finally != nil {
+		defer i.finally(stmt.Finally, env)
+	}
+	caught := i.attempt(stmt.Body, env)
+	if caught == nil {
+		return
+	}
+	scope := object.NewEnclosedEnvironment(env)
+	scope.Set(stmt.Name.Name, caught)
+	i.eval(stmt.Handler, scope)
+}
+
+// attempt runs the block and returns the error that stopped it, as the
+// hash a pidi block is given, or nil when it ran to its end.
+func (i *Interpreter) attempt(block *tree.Block, env *object.Environment) (caught *object.Hash) {
+	depth, current := i.depth, i.current
+	i.trying++
+	defer func() {
+		i.trying--
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, ok := r.(*RuntimeError)
+		if !ok {
+			panic(r)
+		}
+		i.depth, i.current, i.branch = depth, current, nil
+		caught = errorHash(err)
+	}()
+	i.eval(block, env)
+	return nil
+}
+
+// errorHash is the error a pidi block gets, its message and the line and
+// column of the statement it happened in.
+func errorHash(err *RuntimeError) *object.Hash {
+	return &object.Hash{Pairs: map[string]object.Object{
+		"message": text(err.Message),
+		"line":    &object.Integer{Value: int64(err.Pos.Line)},
+		"column":  &object.Integer{Value: int64(err.Pos.Column)},
+	}}
+}
+
+// finally runs the iruthiyaga block, a niruthu or thodar leaving the
+// statement waits for it.
+func (i *Interpreter) finally(block *tree.Block, env *object.Environment) {
+	branch := i.branch
+	i.branch = nil
+	i.eval(block, env)
+	if i.branch == nil {
+		i.branch = branch
+	}
+}
+
+func (i *Interpreter) evalRaiseStatement(stmt *tree.RaiseStmt, env *object.Environment) {
+	switch value := i.evaluateExpression(stmt.Value, env).(type) {
+	case *object.String:
+		i.fatal(value.Value)
+	case *object.Error:
+		i.fatal(value.Message)
+	default:
+		i.fatal(fmt.Sprintf("'eri' needs a String message, got %s", value.Type()))
+	}
+}
+
+// raise stops a muyarchi block at an Error value about to be printed,
+// which outside of one is printed and the program goes on.
+func (i *Interpreter) raise(value object.Object) {
+	if err, ok := value.(*object.Error); ok && i.trying > 0 {
+		i.fatal(err.Message)
+	}
+}
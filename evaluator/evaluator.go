@@ -1,163 +1,401 @@
 package evaluator
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/iam-naveen/compiler/lexer"
 	"github.com/iam-naveen/compiler/object"
 	"github.com/iam-naveen/compiler/tree"
 )
 
-var console = bufio.NewReader(os.Stdin)
-
+// Eval runs node on the default interpreter and exits the process when
+// the program fails.
 func Eval(node tree.Node, env *object.Environment) {
+	if err := std.Run(node, env); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// ReadInput writes the prompt and reads a value on the default
+// interpreter.
+func ReadInput(prompt, datatype string) object.Object {
+	return std.ReadInput(prompt, datatype)
+}
+
+func (i *Interpreter) eval(node tree.Node, env *object.Environment) {
+	stmt, ok := node.(tree.Stmt)
+	if !ok || isBlock(node) {
+		i.dispatch(node, env)
+		return
+	}
+	outer := i.current
+	i.current = stmt
+	if i.Hook != nil {
+		i.Hook.BeforeStatement(stmt, env, i.depth)
+	}
+	i.depth++
+	i.dispatch(node, env)
+	i.depth--
+	i.current = outer
+	if i.Hook != nil {
+		i.Hook.AfterStatement(stmt, env, i.depth)
+	}
+}
+
+func (i *Interpreter) dispatch(node tree.Node, env *object.Environment) {
 	switch node := node.(type) {
 
 	// Statements
 	case *tree.Program:
-		evalProgram(node, env)
+		i.evalProgram(node, env)
 	case *tree.Block:
-		evalBlock(node, env)
+		i.evalBlock(node, env)
 	case *tree.PrintStmt:
-		evalPrintStmt(node, env)
+		i.evalPrintStmt(node, env)
 	case *tree.Input:
-		evalInput(node, env)
+		i.evalInput(node, env)
 	case *tree.Declaration:
-		evalDeclaration(node, env)
+		i.evalDeclaration(node, env)
 	case *tree.IfStmt:
-		evalIfStatement(node, env)
+		i.evalIfStatement(node, env)
 	case *tree.WhileStmt:
-		evalWhileStatement(node, env)
+		i.evalWhileStatement(node, env)
 	case *tree.ForStmt:
-		evalForStatement(node, env)
+		i.evalForStatement(node, env)
+	case *tree.EachStmt:
+		i.evalEachStatement(node, env)
 	case *tree.ExpressionStmt:
-		evalExpressionStatement(node, env)
+		i.evalExpressionStatement(node, env)
+	case *tree.ImportStmt:
+		i.evalImport(node, env)
+	case *tree.BranchStmt:
+		i.branch = node
+	case *tree.TryStmt:
+		i.evalTryStatement(node, env)
+	case *tree.RaiseStmt:
+		i.evalRaiseStatement(node, env)
 
 	default:
-		err := &object.Error{Message: fmt.Sprintf("Unknown Node %T", node)}
-		fmt.Println(err.Inspect())
+		i.report(&object.Error{Message: fmt.Sprintf("Unknown Node %T", node)})
+
+	}
+}
 
+func isBlock(node tree.Node) bool {
+	switch node.(type) {
+	case *tree.Program, *tree.Block:
+		return true
 	}
+	return false
 }
 
-func evalInput(input *tree.Input, env *object.Environment) {
+func (i *Interpreter) evalInput(input *tree.Input, env *object.Environment) {
+	prompt := input.Variable.Name + " = "
+	if input.Prompt != nil {
+		value := i.evaluateExpression(input.Prompt, env)
+		if err, ok := value.(*object.Error); ok {
+			i.fatal(err.Message)
+		}
+		prompt = value.Inspect()
+	}
+	i.call("kodu")
+	env.Set(input.Variable.Name, i.ReadInput(prompt, input.DataType))
+}
 
-	switch input.DataType {
-	case "INTEGER":
-		evalIntegerInput(input, env)
-	case "STRING":
-		evalStringInput(input, env)
-	default:
-		fmt.Println("ERROR: Invalid Input")
-		os.Exit(1)
+// ReadInput writes the prompt and reads a value of the given datatype
+// from the input of the interpreter. It returns null once the input has
+// ended. A malformed number stops the program, or is asked for again
+// when RetryInput is set.
+func (i *Interpreter) ReadInput(prompt, datatype string) object.Object {
+	parse, ok := inputParsers[datatype]
+	if !ok {
+		i.fatal("Invalid Input")
 	}
+	for {
+		fmt.Fprint(i.out(), prompt)
+		line, ok := i.readLine()
+		if !ok {
+			return null
+		}
+		if value := parse(i, line); value != nil {
+			return value
+		}
+		message := fmt.Sprintf("Invalid Input: %q is not %s", line, inputNames[datatype])
+		if !i.RetryInput {
+			i.fatal(message)
+		}
+		fmt.Fprintln(i.out(), message+", try again")
+	}
+}
+
+// inputParsers turn a line of input into a value of each datatype, nil
+// when the line is malformed.
+var inputParsers = map[string]func(i *Interpreter, line string) object.Object{
+	"INTEGER": (*Interpreter).parseInteger,
+	"FLOAT":   (*Interpreter).parseFloat,
+	"STRING": func(i *Interpreter, line string) object.Object {
+		return text(line)
+	},
+}
+
+var inputNames = map[string]string{
+	"INTEGER": "an integer",
+	"FLOAT":   "a number",
 }
 
-func evalIntegerInput(input *tree.Input, env *object.Environment) {
-	var value int64
-	fmt.Print(input.Variable.Name, " = ")
-	_, err := fmt.Scanf("%d", &value)
+func (i *Interpreter) parseInteger(line string) object.Object {
+	value, err := strconv.ParseInt(strings.TrimSpace(line), 10, 64)
+	if errors.Is(err, strconv.ErrRange) && i.BigIntegers {
+		if n, ok := new(big.Int).SetString(strings.TrimSpace(line), 10); ok {
+			return &object.BigInteger{Value: n}
+		}
+	}
 	if err != nil {
-		fmt.Println("ERROR: Invalid Input")
-		os.Exit(1)
+		return nil
 	}
-	env.Set(input.Variable.Name, &object.Integer{Value: value})
+	return &object.Integer{Value: value}
 }
 
-func evalStringInput(input *tree.Input, env *object.Environment) {
-	fmt.Print(input.Variable.Name, " = ")
-	str, err := console.ReadString('\n')
-	str = str[:len(str)-1] // Remove the delimeter '\n'
+func (i *Interpreter) parseFloat(line string) object.Object {
+	value, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
 	if err != nil {
-		fmt.Println("ERROR: Invalid Input")
-		os.Exit(1)
+		return nil
 	}
-	env.Set(input.Variable.Name, &object.String{Value: str})
+	return &object.Float{Value: value}
 }
 
-func evalProgram(program *tree.Program, env *object.Environment) {
-	for _, stmt := range program.Children {
-		Eval(stmt, env)
+// readLine returns the next line of input without its line ending, \n or
+// \r\n, and false when the input has ended.
+func (i *Interpreter) readLine() (string, bool) {
+	line, err := i.input().ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
 	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), true
 }
 
-func evalBlock(block *tree.Block, env *object.Environment) {
-	for _, stmt := range block.Children {
-		Eval(stmt, env)
+func (i *Interpreter) evalProgram(program *tree.Program, env *object.Environment) {
+	for _, stmt := range program.Statements {
+		i.eval(stmt, env)
+		if i.branch != nil {
+			i.fatal(fmt.Sprintf("'%s' can only be used inside a loop", i.branch.Piece.Value))
+		}
 	}
 }
 
-func evalWhileStatement(stmt *tree.WhileStmt, env *object.Environment) {
-	for evaluateExpression(stmt.Condition, env).(*object.Boolean).Value {
-		Eval(stmt.Body, env)
+// evalBlock stops at a niruthu or thodar, leaving the blocks around it
+// up to the loop.
+func (i *Interpreter) evalBlock(block *tree.Block, env *object.Environment) {
+	for _, stmt := range block.Statements {
+		i.eval(stmt, env)
+		if i.branch != nil {
+			return
+		}
+	}
+}
+
+// leave ends a turn of a loop, it reports whether the turn ended with a
+// niruthu.
+func (i *Interpreter) leave() bool {
+	branch := i.branch
+	i.branch = nil
+	return branch != nil && branch.Break()
+}
+
+func (i *Interpreter) evalWhileStatement(stmt *tree.WhileStmt, env *object.Environment) {
+	for {
+		condition, ok := i.evaluateExpression(stmt.Condition, env).(*object.Boolean)
+		if !ok {
+			i.fatal("Non Boolean Expression in Loop Condition")
+		}
+		if !condition.Value {
+			return
+		}
+		i.eval(stmt.Body, env)
+		if i.leave() {
+			return
+		}
 	}
 }
 
-func evalForStatement(stmt *tree.ForStmt, env *object.Environment) {
-	count := evaluateExpression(stmt.Count, env)
+func (i *Interpreter) evalForStatement(stmt *tree.ForStmt, env *object.Environment) {
+	count := i.evaluateExpression(stmt.Count, env)
 	switch count := count.(type) {
 	case *object.Integer:
-		for i := int64(0); i < count.Value; i++ {
-			Eval(stmt.Body, env)
+		for n := int64(0); n < count.Value; n++ {
+			i.eval(stmt.Body, env)
+			if i.leave() {
+				return
+			}
 		}
 	default:
-		fmt.Println("ERROR: Expected Constant Expression in For loop")
-		os.Exit(1)
+		i.fatal("Expected Constant Expression in For loop")
 	}
 
 }
 
-func evalPrintStmt(stmt *tree.PrintStmt, env *object.Environment) {
-	result := evaluateExpression(stmt.Value, env)
-	fmt.Println(result.Inspect())
+func (i *Interpreter) evalPrintStmt(stmt *tree.PrintStmt, env *object.Environment) {
+	results := make([]object.Object, len(stmt.Values))
+	for n, value := range stmt.Values {
+		results[n] = i.evaluateExpression(value, env)
+	}
+	for _, result := range results {
+		i.raise(result)
+	}
+	i.call(lexer.Tanglish(stmt.Piece.Value), results...)
+	fmt.Fprint(i.out(), Printed(results, stmt.Newline()))
 }
 
-func evalDeclaration(decl *tree.Declaration, env *object.Environment) {
-	value := evaluateExpression(decl.Value, env)
-	if value.Type() == object.ERROR_OBJ {
-		fmt.Println(value.Inspect())
-		os.Exit(1)
+// Printed is the text a print statement writes for its values.
+func Printed(values []object.Object, newline bool) string {
+	parts := make([]string, len(values))
+	for n, value := range values {
+		parts[n] = value.Inspect()
+	}
+	text := strings.Join(parts, " ")
+	if newline {
+		text += "\n"
+	}
+	return text
+}
+
+func (i *Interpreter) evalDeclaration(decl *tree.Declaration, env *object.Environment) {
+	value := i.evaluateExpression(decl.Value, env)
+	if err, ok := value.(*object.Error); ok {
+		i.fatal(err.Message)
 	}
 	if decl.Datatype == string(value.Type()) {
 		env.Set(decl.Name.Value, value)
 	} else {
-		fmt.Println("ERROR: Cannot Assign", value.Type(), "to", decl.Datatype, "variable")
-		os.Exit(1)
+		i.fatal(fmt.Sprint("Cannot Assign ", value.Type(), " to ", decl.Datatype, " variable"))
 	}
 }
 
-func evalExpressionStatement(stmt *tree.ExpressionStmt, env *object.Environment) {
+func (i *Interpreter) evalExpressionStatement(stmt *tree.ExpressionStmt, env *object.Environment) {
 	switch expr := stmt.Expression.(type) {
 	case *tree.Assign:
-		evalAssign(expr, env)
+		i.evalAssign(expr, env)
+	case *tree.Call:
+		// a builtin called for its effect, like an assertion, stops the
+		// program when it fails, other expressions are not evaluated
+		if err, ok := i.evaluateExpression(expr, env).(*object.Error); ok {
+			i.fatal(err.Message)
+		}
 	}
 }
 
-func evalIfStatement(stmt *tree.IfStmt, env *object.Environment) {
-	result := evaluateExpression(stmt.Condition, env)
-	if result.Type() != object.BOOLEAN_OBJ {
-		err := &object.Error{Message: "Non Boolean Expression in If Statement"}
-		fmt.Println(err.Inspect())
+// evalIfStatement runs the block of the first branch whose condition is
+// true, or the else block when none is. A condition that is not a Boolean
+// is reported and ends the statement without running any block.
+func (i *Interpreter) evalIfStatement(stmt *tree.IfStmt, env *object.Environment) {
+	if taken, ok := i.test(stmt, stmt.Condition, env); !ok || taken {
+		if taken {
+			i.eval(stmt.Then, env)
+		}
 		return
 	}
-	if result.(*object.Boolean).Value {
-		Eval(stmt.Then, env)
-	} else if stmt.Else != nil {
-		Eval(stmt.Else, env)
+	for _, elseIf := range stmt.ElseIfs {
+		if taken, ok := i.test(elseIf, elseIf.Condition, env); !ok || taken {
+			if taken {
+				i.eval(elseIf.Then, env)
+			}
+			return
+		}
+	}
+	if stmt.Else != nil {
+		i.eval(stmt.Else, env)
 	}
 }
 
-func evalAssign(assign *tree.Assign, env *object.Environment) {
-	value := evaluateExpression(assign.Right, env)
-	env.Set(assign.Left.Name, value)
+// test evaluates the condition of a branch and tells the hook which way
+// it went, ok is false when the condition is not a Boolean.
+func (i *Interpreter) test(branch tree.Node, condition tree.Expr, env *object.Environment) (taken, ok bool) {
+	result, ok := i.evaluateExpression(condition, env).(*object.Boolean)
+	if !ok {
+		i.report(&object.Error{Message: "Non Boolean Expression in If Statement"})
+		return false, false
+	}
+	if i.Hook != nil {
+		i.Hook.OnBranch(branch, result.Value)
+	}
+	return result.Value, true
+}
+
+func (i *Interpreter) evalAssign(assign *tree.Assign, env *object.Environment) {
+	value := i.evaluateExpression(assign.Right, env)
+	if err, ok := value.(*object.Error); ok {
+		i.fatal(err.Message)
+	}
+	env.Assign(assign.Left.Name, value)
+}
+
+// evalEachStatement runs the body in a new scope for each value of the
+// iterable.
+func (i *Interpreter) evalEachStatement(stmt *tree.EachStmt, env *object.Environment) {
+	iterable := i.evaluateExpression(stmt.Iterable, env)
+	if err, ok := iterable.(*object.Error); ok {
+		i.fatal(err.Message)
+	}
+	turn := func(index, element object.Object) bool {
+		scope := object.NewEnclosedEnvironment(env)
+		if stmt.Index != nil {
+			scope.Set(stmt.Index.Name, index)
+		}
+		scope.Set(stmt.Element.Name, element)
+		i.eval(stmt.Body, scope)
+		return !i.leave()
+	}
+	position := func(n int) object.Object {
+		return &object.Integer{Value: int64(n)}
+	}
+	switch iterable := iterable.(type) {
+	case *object.String:
+		for n, letter := range letters(iterable.Value) {
+			if !turn(position(n), text(letter)) {
+				return
+			}
+		}
+	case *object.Array:
+		for n, element := range iterable.Elements {
+			if !turn(position(n), element) {
+				return
+			}
+		}
+	case *object.Hash:
+		pairs := iterable.Pairs
+		for _, key := range iterable.Keys() {
+			element := text(key)
+			if stmt.Index != nil {
+				element = pairs[key]
+			}
+			if !turn(text(key), element) {
+				return
+			}
+		}
+	case *object.Range:
+		for n := iterable.Start; n < iterable.End; n++ {
+			if !turn(&object.Integer{Value: n - iterable.Start}, &object.Integer{Value: n}) {
+				return
+			}
+		}
+	default:
+		i.fatal(fmt.Sprintf("Cannot loop over %s, only over Strings, Arrays, Hashes and ranges", iterable.Type()))
+	}
 }
 
-func evaluateExpression(expr tree.Expr, env *object.Environment) object.Object {
+func (i *Interpreter) evaluateExpression(expr tree.Expr, env *object.Environment) object.Object {
 	switch expr := expr.(type) {
 	case *tree.Number:
 		return &object.Integer{Value: expr.Value}
+	case *tree.Float:
+		return &object.Float{Value: expr.Value}
 	case *tree.StringLiteral:
 		return &object.String{Value: expr.Value}
 	case *tree.Boolean:
@@ -168,144 +406,43 @@ func evaluateExpression(expr tree.Expr, env *object.Environment) object.Object {
 			return &object.Error{Message: "Unknown identifier"}
 		}
 		return res
-	case *tree.Access:
-		left := evaluateExpression(expr.Left, env)
-		index := evaluateExpression(expr.Index, env)
-		switch left := left.(type) {
-		case *object.String:
-			if index.Type() != object.INTEGER_OBJ {
-				return &object.Error{Message: "Index must be an Integer"}
-			}
-			i := index.(*object.Integer).Value
-			if i < 0 || i >= int64(len(left.Value)) {
-				return &object.Error{Message: "Index out of range"}
+	case *tree.Array:
+		elements := make([]object.Object, len(expr.Elements))
+		for n, element := range expr.Elements {
+			value := i.evaluateExpression(element, env)
+			if err, ok := value.(*object.Error); ok {
+				return err
 			}
-			return &object.String{Value: string(left.Value[i])}
+			elements[n] = value
 		}
+		return &object.Array{Elements: elements}
+	case *tree.Access:
+		left := i.evaluateExpression(expr.Left, env)
+		index := i.evaluateExpression(expr.Index, env)
+		return EvalIndex(left, index)
 	case *tree.Length:
-		value := evaluateExpression(expr.Value, env)
-		switch value := value.(type) {
-		case *object.String:
-			return &object.Integer{Value: int64(len(value.Value))}
-		default:
-			return &object.Error{Message: "Length can only be applied to Strings"}
-		}
+		value := i.evaluateExpression(expr.Value, env)
+		i.call("neelam", value)
+		return EvalLength(value)
 	case *tree.Prefix:
-		right := evaluateExpression(expr.Right, env)
-		switch expr.Operator.Kind {
-		case lexer.Minus:
-			if right.Type() != object.INTEGER_OBJ {
-				return &object.Error{Message: "Invalid Operand Type"}
-			}
-			return &object.Integer{Value: -right.(*object.Integer).Value}
-		case lexer.Plus:
-			if right.Type() != object.INTEGER_OBJ {
-				return &object.Error{Message: "Invalid Operand Type"}
-			}
-			return right
-		case lexer.Bang:
-			if right.Type() != object.BOOLEAN_OBJ {
-				return &object.Error{Message: "Invalid Operand Type"}
-			}
-			return &object.Boolean{Value: !right.(*object.Boolean).Value}
-		}
+		right := i.evaluateExpression(expr.Right, env)
+		return evalPrefix(expr.Operator, right, i.BigIntegers)
+	case *tree.Call:
+		return i.evalCall(expr, env)
 	case *tree.Binary:
-		left := evaluateExpression(expr.Left, env)
-		right := evaluateExpression(expr.Right, env)
-		switch expr.Operator.Kind {
-		case lexer.Plus:
-			return evaluatePlus(left, right)
-		case lexer.Minus:
-			return evaluateMinus(left, right)
-		case lexer.Star:
-			return evaluateMultiply(left, right)
-		case lexer.Slash:
-			return evaluateDivide(left, right)
-		case lexer.Percent:
-			return &object.Integer{Value: left.(*object.Integer).Value % right.(*object.Integer).Value}
-		case lexer.Equal:
-			return &object.Boolean{Value: left.Inspect() == right.Inspect()}
-		case lexer.NotEqual:
-			return &object.Boolean{Value: left.Inspect() != right.Inspect()}
-		case lexer.Less:
-			return &object.Boolean{Value: left.(*object.Integer).Value < right.(*object.Integer).Value}
-		case lexer.Greater:
-			return &object.Boolean{Value: left.(*object.Integer).Value > right.(*object.Integer).Value}
-		case lexer.LessEqual:
-			return &object.Boolean{Value: left.(*object.Integer).Value <= right.(*object.Integer).Value}
-		case lexer.GreaterEqual:
-			return &object.Boolean{Value: left.(*object.Integer).Value >= right.(*object.Integer).Value}
-		case lexer.And:
-			return &object.Boolean{Value: left.(*object.Boolean).Value && right.(*object.Boolean).Value}
-		case lexer.Or:
-			return &object.Boolean{Value: left.(*object.Boolean).Value || right.(*object.Boolean).Value}
-		default:
-			msg := fmt.Sprintf(
-				"Unknown Operator '%s' for %s",
-				expr.Operator.Value,
-				left.Type(),
-			)
-			return &object.Error{Message: msg}
-		}
+		left := i.evaluateExpression(expr.Left, env)
+		right := i.evaluateExpression(expr.Right, env)
+		return evalBinary(expr.Operator, left, right, i.BigIntegers)
 	}
 	return &object.Error{Message: "Unknown expression"}
 }
 
-func evaluatePlus(left, right object.Object) object.Object {
-	switch left := left.(type) {
-	case *object.Integer:
-		if right.Type() == object.STRING_OBJ {
-			return &object.String{Value: fmt.Sprintf("%d%s", left.Value, right.(*object.String).Value)}
-		}
-		return &object.Integer{Value: left.Value + right.(*object.Integer).Value}
-	case *object.String:
-		if right.Type() == object.INTEGER_OBJ {
-			return &object.String{Value: left.Value + fmt.Sprintf("%d", right.(*object.Integer).Value)}
-		}
-		return &object.String{Value: string(left.Value) + right.(*object.String).Value}
-	default:
-		return &object.Error{Message: "Unknown Type"}
-	}
-}
-
-func evaluateMinus(left, right object.Object) object.Object {
-	if left.Type() != right.Type() {
-		return &object.Error{Message: fmt.Sprintf("Type Mismatch: Cannot perform operation with %s and %s", left.Type(), right.Type())}
-	}
-	switch left := left.(type) {
-	case *object.Integer:
-		return &object.Integer{Value: left.Value - right.(*object.Integer).Value}
-	case *object.String:
-		return &object.Error{Message: "Cannot Subtract Strings"}
-	default:
-		return &object.Error{Message: "Unknown Type"}
-	}
-}
-
-func evaluateMultiply(left, right object.Object) object.Object {
-	if left.Type() != right.Type() {
-		return &object.Error{Message: fmt.Sprintf("Type Mismatch: Cannot perform operation with %s and %s", left.Type(), right.Type())}
+func evaluatePlus(left, right object.Object, promote bool) object.Object {
+	if left.Type() != object.STRING_OBJ && right.Type() != object.STRING_OBJ {
+		return arithmetic(lexer.Plus, left, right, promote)
 	}
-	switch left := left.(type) {
-	case *object.Integer:
-		return &object.Integer{Value: left.Value * right.(*object.Integer).Value}
-	case *object.String:
-		return &object.Error{Message: "Cannot Multiply Strings"}
-	default:
-		return &object.Error{Message: "Unknown Type"}
-	}
-}
-
-func evaluateDivide(left, right object.Object) object.Object {
-	if left.Type() != right.Type() {
-		return &object.Error{Message: fmt.Sprintf("Type Mismatch: Cannot perform operation with %s and %s", left.Type(), right.Type())}
-	}
-	switch left := left.(type) {
-	case *object.Integer:
-		return &object.Integer{Value: left.Value / right.(*object.Integer).Value}
-	case *object.String:
-		return &object.Error{Message: "Cannot Divide Strings"}
-	default:
+	if (!isNumber(left) && left.Type() != object.STRING_OBJ) || (!isNumber(right) && right.Type() != object.STRING_OBJ) {
 		return &object.Error{Message: "Unknown Type"}
 	}
+	return &object.String{Value: left.Inspect() + right.Inspect()}
 }
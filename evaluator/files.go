@@ -0,0 +1,133 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+// The file builtins only reach the files under the Files directory of
+// the interpreter, a path leaving it, by .. or by a symbolic link, is
+// denied like every path is when Files is empty.
+
+// sandbox returns the file a builtin may use for the path a program gave,
+// or the error telling why it may not.
+func (i *Interpreter) sandbox(builtin, name string) (string, *object.Error) {
+	if i.Files == "" {
+		return "", &object.Error{Message: fmt.Sprintf("%s: permission denied, file access is turned off", builtin)}
+	}
+	root, err := filepath.Abs(i.Files)
+	if err != nil {
+		return "", &object.Error{Message: fmt.Sprintf("%s: %s", builtin, err)}
+	}
+	root = followLinks(root)
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = followLinks(filepath.Clean(path))
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &object.Error{Message: fmt.Sprintf("%s: permission denied, %s is outside of %s", builtin, name, i.Files)}
+	}
+	return path, nil
+}
+
+// followLinks follows the symbolic links of the part of path that exists.
+func followLinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	dir := filepath.Dir(path)
+	if dir == path {
+		return path
+	}
+	return filepath.Join(followLinks(dir), filepath.Base(path))
+}
+
+func fileError(builtin, name string, err error) *object.Error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return &object.Error{Message: fmt.Sprintf("%s: %s: %s", builtin, name, err)}
+}
+
+func readFile(i *Interpreter, args []object.Object) object.Object {
+	path, denied := i.sandbox("read_file", str(args[0]))
+	if denied != nil {
+		return denied
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileError("read_file", str(args[0]), err)
+	}
+	return text(string(data))
+}
+
+// readLines splits a file at line ends, \r\n included, the end of the
+// last line does not start another.
+func readLines(i *Interpreter, args []object.Object) object.Object {
+	path, denied := i.sandbox("read_lines", str(args[0]))
+	if denied != nil {
+		return denied
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileError("read_lines", str(args[0]), err)
+	}
+	lines := []object.Object{}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return &object.Array{Elements: lines}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		lines = append(lines, text(strings.TrimSuffix(line, "\r")))
+	}
+	return &object.Array{Elements: lines}
+}
+
+func writeFile(i *Interpreter, args []object.Object) object.Object {
+	return i.write("write_file", str(args[0]), str(args[1]), os.O_TRUNC)
+}
+
+func appendFile(i *Interpreter, args []object.Object) object.Object {
+	return i.write("append_file", str(args[0]), str(args[1]), os.O_APPEND)
+}
+
+// write creates the file when it is missing, but not the directory it
+// is in.
+func (i *Interpreter) write(builtin, name, content string, mode int) object.Object {
+	path, denied := i.sandbox(builtin, name)
+	if denied != nil {
+		return denied
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|mode, 0644)
+	if err != nil {
+		return fileError(builtin, name, err)
+	}
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fileError(builtin, name, err)
+	}
+	return null
+}
+
+func fileExists(i *Interpreter, args []object.Object) object.Object {
+	path, denied := i.sandbox("file_exists", str(args[0]))
+	if denied != nil {
+		return denied
+	}
+	_, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fileError("file_exists", str(args[0]), err)
+	}
+	return &object.Boolean{Value: err == nil}
+}
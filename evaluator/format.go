@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/grapheme"
+	"github.com/iam-naveen/compiler/object"
+)
+
+// format fills the placeholders of a template with the values of an
+// array, in order. A placeholder is {} or {:spec}, the spec being
+//
+//	[[fill]align][0][width][base]
+//
+// where align is < for left, > for right and ^ for the middle, numbers
+// go right and other values left when it is not given. The value is
+// padded with fill, a space unless given, to width letters. A 0 before
+// the width pads a number with zeros after its sign instead. The base is
+// b, o, d, x or X and writes an Integer in binary, octal, decimal or
+// hexadecimal. {{ and }} write a single brace.
+func format(i *Interpreter, args []object.Object) object.Object {
+	template, values := str(args[0]), args[1].(*object.Array).Elements
+	out := strings.Builder{}
+	used := 0
+	for len(template) > 0 {
+		switch {
+		case strings.HasPrefix(template, "{{"), strings.HasPrefix(template, "}}"):
+			out.WriteByte(template[0])
+			template = template[2:]
+			continue
+		case template[0] == '}':
+			return &object.Error{Message: "format: a } is not closing a placeholder, write }} for a brace"}
+		case template[0] != '{':
+			out.WriteByte(template[0])
+			template = template[1:]
+			continue
+		}
+		end := strings.IndexByte(template, '}')
+		if end < 0 {
+			return &object.Error{Message: fmt.Sprintf("format: the placeholder %s is not closed", template)}
+		}
+		placeholder := template[:end+1]
+		template = template[end+1:]
+		spec, ok := strings.CutPrefix(placeholder[1:end], ":")
+		if !ok && spec != "" {
+			return &object.Error{Message: fmt.Sprintf("format: %s is not a placeholder, the spec starts with :", placeholder)}
+		}
+		if used == len(values) {
+			return &object.Error{Message: fmt.Sprintf("format: placeholder %d has no value, the array has %d", used+1, len(values))}
+		}
+		s, err := fill(placeholder, spec, values[used])
+		if err != nil {
+			return err
+		}
+		out.WriteString(s)
+		used++
+	}
+	if used < len(values) {
+		return &object.Error{Message: fmt.Sprintf("format: %d values for %d placeholders", len(values), used)}
+	}
+	return text(out.String())
+}
+
+// maxWidth bounds the width of a placeholder.
+const maxWidth = 1 << 16
+
+// fill writes one value as the spec of its placeholder says.
+func fill(placeholder, spec string, value object.Object) (string, *object.Error) {
+	pad, align := " ", ""
+	if first, size := utf8.DecodeRuneInString(spec); size < len(spec) && strings.ContainsRune("<>^", rune(spec[size])) {
+		pad, align, spec = string(first), spec[size:size+1], spec[size+1:]
+	} else if spec != "" && strings.ContainsRune("<>^", rune(spec[0])) {
+		align, spec = spec[:1], spec[1:]
+	}
+	zero := strings.HasPrefix(spec, "0")
+	if zero {
+		spec = spec[1:]
+	}
+	digits := len(spec) - len(strings.TrimLeft(spec, "0123456789"))
+	width := 0
+	if digits > 0 {
+		n, err := strconv.Atoi(spec[:digits])
+		if err != nil || n > maxWidth {
+			return "", &object.Error{Message: fmt.Sprintf("format: the width of %s is above %d", placeholder, maxWidth)}
+		}
+		width, spec = n, spec[digits:]
+	}
+	base := 10
+	switch spec {
+	case "":
+	case "b":
+		base = 2
+	case "o":
+		base = 8
+	case "d":
+	case "x", "X":
+		base = 16
+	default:
+		return "", &object.Error{Message: fmt.Sprintf("format: %s is not a placeholder", placeholder)}
+	}
+
+	s := value.Inspect()
+	_, number := value.(*object.Float)
+	switch n := value.(type) {
+	case *object.Integer:
+		s, number = strconv.FormatInt(n.Value, base), true
+	case *object.BigInteger:
+		s, number = n.Value.Text(base), true
+	default:
+		if base != 10 || spec == "d" {
+			return "", &object.Error{Message: fmt.Sprintf("format: %s needs an Integer, got %s", placeholder, value.Type())}
+		}
+	}
+	if spec == "X" {
+		s = strings.ToUpper(s)
+	}
+	if zero && !number {
+		return "", &object.Error{Message: fmt.Sprintf("format: %s pads numbers with zeros, got %s", placeholder, value.Type())}
+	}
+
+	missing := width - grapheme.Count(s)
+	if missing <= 0 {
+		return s, nil
+	}
+	if zero && align == "" {
+		sign := ""
+		if strings.HasPrefix(s, "-") {
+			sign, s = "-", s[1:]
+		}
+		return sign + strings.Repeat("0", missing) + s, nil
+	}
+	if align == "" {
+		align = "<"
+		if number {
+			align = ">"
+		}
+	}
+	switch align {
+	case "<":
+		return s + strings.Repeat(pad, missing), nil
+	case ">":
+		return strings.Repeat(pad, missing) + s, nil
+	}
+	return strings.Repeat(pad, missing/2) + s + strings.Repeat(pad, missing-missing/2), nil
+}
@@ -0,0 +1,190 @@
+package evaluator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Interpreter evaluates programs. The zero value reads from os.Stdin and
+// writes to os.Stdout.
+//
+// A runtime error either stops the program or is printed for it to go
+// on, like the vm does. An error in a declaration, an assignment, a call
+// made as a statement, a loop or an import stops it, and Run returns it.
+// An Error value printed by sollu and an if condition that is not a
+// Boolean are printed as an ERROR line instead. Inside a muyarchi block
+// both kinds stop the block and go to its pidi block, so a print of an
+// error that goes on outside of one stops inside of it.
+type Interpreter struct {
+	In   io.Reader
+	Out  io.Writer
+	Hook Hook
+	// Path is the file of the program, the files it imports are found
+	// relative to it, or to the current directory when it is empty.
+	Path string
+	// BigIntegers lets integers grow past 64 bits instead of failing
+	// with an overflow.
+	BigIntegers bool
+	// Rand makes the numbers of the random builtins. A nil Rand is seeded
+	// from the time when first used, seed one to repeat a run.
+	Rand *rand.Rand
+	// Files is the directory the file builtins may use, relative paths
+	// are found in it. The builtins are denied when it is empty.
+	Files string
+	// RetryInput asks for a number again when the line read is not one,
+	// instead of stopping the program.
+	RetryInput bool
+
+	console   *bufio.Reader
+	depth     int                // statements being run around the current one
+	current   tree.Stmt          // the innermost statement being run
+	modules   map[string]*module // by absolute path
+	importing []*module          // the modules running, innermost last
+	branch    *tree.BranchStmt   // the niruthu or thodar leaving the blocks of a loop
+	trying    int                // muyarchi blocks being run, errors in them are caught
+}
+
+// Hook watches a program run. It is told about each statement before and
+// after it runs, along with the environment it runs in and how many
+// statements enclose it, about the way each condition of an if statement
+// or of one of its else ifs goes, about each builtin called and about
+// each error stopping a statement or printed by the interpreter, also
+// when a muyarchi block catches it. An Error value printed by sollu is
+// only told about inside of a muyarchi block, where it stops the block.
+// A statement stopped by a runtime error is not reported as finished.
+type Hook interface {
+	BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int)
+	AfterStatement(stmt tree.Stmt, env *object.Environment, depth int)
+	OnBranch(branch tree.Node, taken bool)
+	OnCall(name string, args []object.Object)
+	OnError(stmt tree.Stmt, err *object.Error)
+}
+
+// NopHook does nothing, hooks embed it to only implement what they need.
+type NopHook struct{}
+
+func (NopHook) BeforeStatement(tree.Stmt, *object.Environment, int) {}
+func (NopHook) AfterStatement(tree.Stmt, *object.Environment, int)  {}
+func (NopHook) OnBranch(tree.Node, bool)                            {}
+func (NopHook) OnCall(string, []object.Object)                      {}
+func (NopHook) OnError(tree.Stmt, *object.Error)                    {}
+
+// Hooks tells every hook in order.
+type Hooks []Hook
+
+func (h Hooks) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	for _, hook := range h {
+		hook.BeforeStatement(stmt, env, depth)
+	}
+}
+
+func (h Hooks) AfterStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	for _, hook := range h {
+		hook.AfterStatement(stmt, env, depth)
+	}
+}
+
+func (h Hooks) OnBranch(branch tree.Node, taken bool) {
+	for _, hook := range h {
+		hook.OnBranch(branch, taken)
+	}
+}
+
+func (h Hooks) OnCall(name string, args []object.Object) {
+	for _, hook := range h {
+		hook.OnCall(name, args)
+	}
+}
+
+func (h Hooks) OnError(stmt tree.Stmt, err *object.Error) {
+	for _, hook := range h {
+		hook.OnError(stmt, err)
+	}
+}
+
+// RuntimeError stops the program. Pos is the start of the statement that
+// failed, when the program has positions.
+type RuntimeError struct {
+	Message string
+	Pos     lexer.Position
+}
+
+func (e *RuntimeError) Error() string {
+	return "ERROR: " + e.Message
+}
+
+// std is the interpreter behind Eval and ReadInput.
+var std = &Interpreter{}
+
+// Run evaluates node and returns the error that stopped it, if any.
+func (i *Interpreter) Run(node tree.Node, env *object.Environment) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			i.depth, i.current, i.branch, i.trying = 0, nil, nil, 0
+			runtime, ok := r.(*RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = runtime
+		}
+	}()
+	i.eval(node, env)
+	return nil
+}
+
+func (i *Interpreter) fatal(message string) {
+	if i.Hook != nil {
+		i.Hook.OnError(i.current, &object.Error{Message: message})
+	}
+	err := &RuntimeError{Message: message}
+	if i.current != nil {
+		err.Pos = tree.SpanOf(i.current).Start
+	}
+	err.Message = i.locate(message, err.Pos)
+	panic(err)
+}
+
+// report prints an error the program goes on after, in a muyarchi block
+// it stops the block instead.
+func (i *Interpreter) report(err *object.Error) {
+	if i.trying > 0 {
+		i.fatal(err.Message)
+	}
+	if i.Hook != nil {
+		i.Hook.OnError(i.current, err)
+	}
+	fmt.Fprintln(i.out(), err.Inspect())
+}
+
+func (i *Interpreter) call(name string, args ...object.Object) {
+	if i.Hook != nil {
+		i.Hook.OnCall(name, args)
+	}
+}
+
+func (i *Interpreter) out() io.Writer {
+	if i.Out == nil {
+		return os.Stdout
+	}
+	return i.Out
+}
+
+// input is shared by every read, so text buffered by one read is seen by
+// the next.
+func (i *Interpreter) input() *bufio.Reader {
+	if i.console == nil {
+		if i.In == nil {
+			i.console = bufio.NewReader(os.Stdin)
+		} else {
+			i.console = bufio.NewReader(i.In)
+		}
+	}
+	return i.console
+}
@@ -0,0 +1,347 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+func parse(input string) *tree.Program {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return parser.Parse(channel, false)
+}
+
+func TestInputAndOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+	interpreter := &Interpreter{In: strings.NewReader("41\nvanakkam\n"), Out: out}
+	err := interpreter.Run(parse(`yen a kodu; sol s kodu; a + 1 sollu; s sollu; s, a solli; "!" sollu;`), object.NewEnvironment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "a = s = 42\nvanakkam\nvanakkam 41!\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		stdin    string
+		retry    bool
+		expected string
+	}{
+		{`sol name = "Name? " kodu; "hi", name sollu;`, "nila\n", false, "Name? hi nila\n"},
+		{`yen a = "a" + 1 + ": " kodu; a sollu;`, "7\n", false, "a1: 7\n"},
+		{`sol a kodu; sol b kodu; a neelam, b neelam sollu;`, "ab\r\ncd\r\n", false, "a = b = 2 2\n"},
+		{`sol a kodu; a sollu;`, "last", false, "a = last\n"},
+		// every read after the end gives null
+		{`sol a kodu; yen b kodu; is_null(a), is_null(b), a sollu;`, "", false, "a = b = true true null\n"},
+		{`sol a kodu; sol b kodu; is_null(a), is_null(b) sollu;`, "\n", false, "a = b = false true\n"},
+		{`yen a kodu; a + 1 sollu;`, "x\n 41 \n", true, "a = Invalid Input: \"x\" is not an integer, try again\na = 42\n"},
+		{`yen a kodu; is_null(a) sollu;`, "x\n", true, "a = Invalid Input: \"x\" is not an integer, try again\na = true\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		interpreter := &Interpreter{In: strings.NewReader(tt.stdin), Out: out, RetryInput: tt.retry}
+		if err := interpreter.Run(parse(tt.input), object.NewEnvironment()); err != nil {
+			t.Errorf("%s: %s", tt.input, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: got %q, want %q", tt.input, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestRuntimeError(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`yen a = "x";`, "ERROR: Cannot Assign STRING to INTEGER variable"},
+		{`yen a = b;`, "ERROR: Unknown identifier"},
+		{`"x" murai { }`, "ERROR: Expected Constant Expression in For loop"},
+		{`yen a kodu;`, `ERROR: Invalid Input: "x" is not an integer`},
+		{`thasamam a = "f?" kodu;`, `ERROR: Invalid Input: "x" is not a number`},
+		{`sol s = b kodu;`, "ERROR: Unknown identifier"},
+	}
+	for _, tt := range tests {
+		interpreter := &Interpreter{In: strings.NewReader("x\n"), Out: &bytes.Buffer{}}
+		err := interpreter.Run(parse(tt.input), object.NewEnvironment())
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestBranch(t *testing.T) {
+	out := &bytes.Buffer{}
+	interpreter := &Interpreter{Out: out}
+	program := parse(`yen i = 0; i < 9 varaikkum { i = i + 1; 3 murai { aam endral { i > 2 endral { niruthu; } } } i == 2 endral { thodar; } i == 4 endral { niruthu; } i sollu; } "done", i sollu;`)
+	if err := interpreter.Run(program, object.NewEnvironment()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "1\n3\ndone 4\n" {
+		t.Errorf("got %q", out.String())
+	}
+	// a tree not made by the parser can have one outside of a loop
+	program = &tree.Program{Statements: []tree.Stmt{&tree.BranchStmt{Piece: lexer.Piece{Kind: lexer.Break, Value: "niruthu"}}}}
+	err := interpreter.Run(program, object.NewEnvironment())
+	if err == nil || err.Error() != "ERROR: 'niruthu' can only be used inside a loop" {
+		t.Errorf("got %v", err)
+	}
+}
+
+// TestIfChains runs every if with up to two else ifs, with and without
+// an else, for every way their conditions can go.
+func TestIfChains(t *testing.T) {
+	for elseIfs := 0; elseIfs <= 2; elseIfs++ {
+		for _, hasElse := range []bool{false, true} {
+			for truth := 0; truth < 1<<(elseIfs+1); truth++ {
+				source, expected := "", ""
+				for n := 0; n <= elseIfs; n++ {
+					condition := "illai"
+					if truth&(1<<n) != 0 {
+						condition = "aam"
+						if expected == "" {
+							expected = fmt.Sprintf("%d\n", n)
+						}
+					}
+					if n > 0 {
+						source += " illana "
+					}
+					source += fmt.Sprintf("%s endral { %d sollu; }", condition, n)
+				}
+				if hasElse {
+					source += ` illana { "else" sollu; }`
+					if expected == "" {
+						expected = "else\n"
+					}
+				}
+				source += ` "done" sollu;`
+				out := &bytes.Buffer{}
+				if err := (&Interpreter{Out: out}).Run(parse(source), object.NewEnvironment()); err != nil {
+					t.Fatalf("%s: %v", source, err)
+				}
+				if out.String() != expected+"done\n" {
+					t.Errorf("%s: got %q, want %q", source, out.String(), expected+"done\n")
+				}
+			}
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"தமிழ்" ovvonrum l { l solli; "|" solli; }`, "த|மி|ழ்|"},
+		{`"ab" ovvonrum i, l { i, l sollu; }`, "0 a\n1 b\n"},
+		{`varisai xs = [3, 5]; xs ஒவ்வொன்றும் i, x { i * x sollu; }`, "0\n5\n"},
+		{`[] ovvonrum x { x sollu; } "empty" sollu;`, "empty\n"},
+		{`agarathi h = json_decode(data); h ovvonrum k { k sollu; } h ovvonrum k, v { k, v sollu; }`, "a\nb\na 1\nb 2\n"},
+		{`range(2, 5) ovvonrum i, n { i, n sollu; }`, "0 2\n1 3\n2 4\n"},
+		{`range(5, 2) ovvonrum n { n sollu; } "none" sollu;`, "none\n"},
+		{`range(0, 9) ovvonrum n { n == 1 endral { thodar; } n == 3 endral { niruthu; } n sollu; }`, "0\n2\n"},
+		// every turn has a scope of its own, assignments reach the outside
+		{`yen total = 0; [1, 2, 3] ovvonrum x { yen double = x * 2; total = total + double; } total sollu;`, "12\n"},
+		{`yen x = 7; [1] ovvonrum x { x sollu; } x sollu;`, "1\n7\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		interpreter := &Interpreter{Out: out}
+		env := object.NewEnvironment()
+		env.Set("data", &object.String{Value: `{"b": 2, "a": 1}`})
+		if err := interpreter.Run(parse(tt.input), env); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: got %q, want %q", tt.input, out.String(), tt.expected)
+		}
+	}
+	err := (&Interpreter{Out: &bytes.Buffer{}}).Run(parse(`5 ovvonrum x { x sollu; }`), object.NewEnvironment())
+	if err == nil || err.Error() != "ERROR: Cannot loop over INTEGER, only over Strings, Arrays, Hashes and ranges" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestTry(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"muyarchi { 1 sollu; } pidi e { 2 sollu; }", "1\n"},
+		// the error of the statement that failed, and where it is
+		{"muyarchi {\n  yen a = \"x\";\n  \"never\" sollu;\n} pidi e {\n  e[\"message\"], e[\"line\"], e[\"column\"] sollu;\n}",
+			"Cannot Assign STRING to INTEGER variable 2 3\n"},
+		{`muyarchi { "oops" eri; } பிடி e { e sollu; }`, `{"column": 12, "line": 1, "message": "oops"}` + "\n"},
+		// errors printed or stored outside are caught inside
+		{`muyarchi { x sollu; } pidi e { e["message"] sollu; } x sollu;`, "Unknown identifier\nERROR: Unknown identifier\n"},
+		{`yen a = 1; muyarchi { a = a / 0; } pidi e { e["message"] sollu; }`, "Division by zero\n"},
+		{`muyarchi { 1 endral { } } pidi e { e["message"] sollu; }`, "Non Boolean Expression in If Statement\n"},
+		{`muyarchi { "a" eri; } pidi e { } iruthiyaga { "f" sollu; } muyarchi { } pidi e { } இறுதியாக { "g" sollu; }`, "f\ng\n"},
+		// an error in pidi goes to the muyarchi around it, after iruthiyaga
+		{`muyarchi { muyarchi { "a" eri; } pidi e { e["message"] + "b" eri; } iruthiyaga { "f" sollu; } } pidi e { e["message"] sollu; }`,
+			"f\nab\n"},
+		{`muyarchi { muyarchi { "a" eri; } pidi e { } "after" sollu; "c" eri; } pidi e { e["message"] sollu; }`, "after\nc\n"},
+		// the name belongs to pidi
+		{`sol e = "outer"; muyarchi { "x" eri; } pidi e { } e sollu;`, "outer\n"},
+		// niruthu and thodar leave after iruthiyaga
+		{`yen i = 0; i < 4 varaikkum { i = i + 1; muyarchi { i == 2 endral { thodar; } i == 3 endral { niruthu; } i sollu; } pidi e { } iruthiyaga { "f", i sollu; } }`,
+			"1\nf 1\nf 2\nf 3\n"},
+		{`3 murai { muyarchi { "x" eri; } pidi e { niruthu; } } "done" sollu;`, "done\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		if err := (&Interpreter{Out: out}).Run(parse(tt.input), object.NewEnvironment()); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: got %q, want %q", tt.input, out.String(), tt.expected)
+		}
+	}
+	errors := []struct {
+		input string
+		err   string
+	}{
+		{`"a" sollu; "stop" eri; "b" sollu;`, "ERROR: stop"},
+		{`1 + 2 eri;`, "ERROR: 'eri' needs a String message, got INTEGER"},
+		{`muyarchi { "a" eri; } pidi e { "b" eri; } iruthiyaga { "f" sollu; }`, "ERROR: b"},
+	}
+	for _, tt := range errors {
+		err := (&Interpreter{Out: &bytes.Buffer{}}).Run(parse(tt.input), object.NewEnvironment())
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got %v, want %s", tt.input, err, tt.err)
+		}
+	}
+}
+
+// recorder writes +line:depth before and -line:depth after statements.
+type recorder []string
+
+func (r *recorder) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	*r = append(*r, fmt.Sprintf("+%d:%d", tree.SpanOf(stmt).Start.Line, depth))
+}
+
+func (r *recorder) AfterStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	*r = append(*r, fmt.Sprintf("-%d:%d", tree.SpanOf(stmt).Start.Line, depth))
+}
+
+func (r *recorder) OnBranch(branch tree.Node, taken bool) {
+	*r = append(*r, fmt.Sprintf("branch@%d(%t)", tree.SpanOf(branch).Start.Line, taken))
+}
+
+func (r *recorder) OnCall(name string, args []object.Object) {
+	values := []string{}
+	for _, arg := range args {
+		values = append(values, arg.Inspect())
+	}
+	*r = append(*r, fmt.Sprintf("%s(%s)", name, strings.Join(values, ",")))
+}
+
+func (r *recorder) OnError(stmt tree.Stmt, err *object.Error) {
+	*r = append(*r, fmt.Sprintf("error@%d(%s)", tree.SpanOf(stmt).Start.Line, err.Message))
+}
+
+// errorHook records the errors a hook is told about.
+type errorHook struct {
+	NopHook
+	messages []string
+}
+
+func (h *errorHook) OnError(stmt tree.Stmt, err *object.Error) {
+	h.messages = append(h.messages, err.Message)
+}
+
+// Each statement kind either stops the program or prints the error and
+// goes on, a muyarchi block catches both.
+func TestErrorModel(t *testing.T) {
+	tests := []struct {
+		kind      string
+		statement string
+		message   string
+		stops     bool // the program outside of muyarchi, instead of printing it
+	}{
+		{"declaration", `yen a = 1 / 0;`, "Division by zero", true},
+		{"assignment", `yen a = 1; a = a / 0;`, "Division by zero", true},
+		{"call", `parse_int("x");`, `parse_int: "x" is not an integer`, true},
+		{"print", `1 / 0 sollu;`, "Division by zero", false},
+		{"condition", `1 endral { }`, "Non Boolean Expression in If Statement", false},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		err := (&Interpreter{Out: out}).Run(parse(tt.statement+` "after" sollu;`), object.NewEnvironment())
+		if tt.stops && (err == nil || err.Error() != "ERROR: "+tt.message || out.Len() != 0) {
+			t.Errorf("%s: got %q and %v, want it to stop with %s", tt.kind, out.String(), err, tt.message)
+		}
+		if !tt.stops && (err != nil || out.String() != "ERROR: "+tt.message+"\nafter\n") {
+			t.Errorf("%s: got %q and %v, want it to print %s and go on", tt.kind, out.String(), err, tt.message)
+		}
+
+		out.Reset()
+		hook := &errorHook{}
+		input := `muyarchi { ` + tt.statement + ` "never" sollu; } pidi e { e["message"] sollu; } "after" sollu;`
+		if err := (&Interpreter{Out: out, Hook: hook}).Run(parse(input), object.NewEnvironment()); err != nil {
+			t.Errorf("%s in muyarchi: %v", tt.kind, err)
+		}
+		if out.String() != tt.message+"\nafter\n" {
+			t.Errorf("%s in muyarchi: got %q, want %q", tt.kind, out.String(), tt.message+"\nafter\n")
+		}
+		if len(hook.messages) != 1 || hook.messages[0] != tt.message {
+			t.Errorf("%s in muyarchi: the hook was told %q", tt.kind, hook.messages)
+		}
+	}
+}
+
+func TestHook(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"yen i = 0;\n2 murai {\n    i = i + 1;\n    aam endral {\n        i sollu;\n    }\n}\n",
+			"+1:0 -1:0 +2:0 +3:1 -3:1 +4:1 branch@4(true) +5:2 sollu(1) -5:2 -4:1 +3:1 -3:1 +4:1 branch@4(true) +5:2 sollu(2) -5:2 -4:1 -2:0",
+		},
+		{
+			"sol s = \"ab\";\ns neelam sollu;\n1 endral {\n}\nyen a = s;\n\"x\" sollu;\n",
+			"+1:0 -1:0 +2:0 neelam(ab) sollu(2) -2:0 +3:0 error@3(Non Boolean Expression in If Statement) -3:0 +5:0 error@5(Cannot Assign STRING to INTEGER variable)",
+		},
+		{
+			// each else if tested is a branch, the ones after the taken one are not
+			"illai endral {\n} illana 1 > 2 endral {\n} illana aam endral {\n    \"x\" sollu;\n} illana aam endral {\n} illana {\n}\n",
+			"+1:0 branch@1(false) branch@2(false) branch@3(true) +4:1 sollu(x) -4:1 -1:0",
+		},
+		{
+			"illai endral {\n} illana 1 endral {\n} illana {\n    \"x\" sollu;\n}\n\"y\" sollu;\n",
+			"+1:0 branch@1(false) error@1(Non Boolean Expression in If Statement) -1:0 +6:0 sollu(y) -6:0",
+		},
+	}
+	for _, tt := range tests {
+		hook := &recorder{}
+		interpreter := &Interpreter{Out: &bytes.Buffer{}, Hook: hook}
+		interpreter.Run(parse(tt.input), object.NewEnvironment())
+		if got := strings.Join(*hook, " "); got != tt.expected {
+			t.Errorf("got %s\nwant %s", got, tt.expected)
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	interpreter := &Interpreter{Out: &bytes.Buffer{}, Hook: Hooks{first, second}}
+	if err := interpreter.Run(parse("1 sollu;\n"), object.NewEnvironment()); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(*first, " ") + " | " + strings.Join(*second, " "); got != "+1:0 sollu(1) -1:0 | +1:0 sollu(1) -1:0" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+// JSON objects become hashes and numbers become Integers, or Floats when
+// they have a fraction or an exponent. Encoding writes the keys of a hash
+// in sorted order, so a value is always written the same.
+
+func jsonDecode(i *Interpreter, args []object.Object) object.Object {
+	s := str(args[0])
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return jsonError(s, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return &object.Error{Message: fmt.Sprintf("json_decode: unexpected text after the value at offset %d", decoder.InputOffset())}
+	}
+	if err := i.checkNumbers(s); err != nil {
+		return err
+	}
+	return i.fromJSON(value)
+}
+
+// checkNumbers reads the valid JSON in s again for a number too large for
+// the value it becomes, and tells where it starts.
+func (i *Interpreter) checkNumbers(s string) *object.Error {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		number, ok := token.(json.Number)
+		if !ok {
+			continue
+		}
+		if _, ok := i.fromNumber(number).(*object.Error); ok {
+			start := decoder.InputOffset() - int64(len(number))
+			return &object.Error{Message: fmt.Sprintf("json_decode: %s is out of range at offset %d", number, start)}
+		}
+	}
+}
+
+// jsonError tells where in s decoding failed.
+func jsonError(s string, err error) object.Object {
+	var syntax *json.SyntaxError
+	switch {
+	case errors.As(err, &syntax):
+		return &object.Error{Message: fmt.Sprintf("json_decode: %s at offset %d", syntax, syntax.Offset)}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &object.Error{Message: fmt.Sprintf("json_decode: unexpected end of JSON input at offset %d", len(s))}
+	}
+	return &object.Error{Message: "json_decode: " + err.Error()}
+}
+
+func (i *Interpreter) fromJSON(value any) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return null
+	case bool:
+		return &object.Boolean{Value: value}
+	case string:
+		return text(value)
+	case json.Number:
+		return i.fromNumber(value)
+	case []any:
+		elements := make([]object.Object, len(value))
+		for n, element := range value {
+			elements[n] = i.fromJSON(element)
+		}
+		return &object.Array{Elements: elements}
+	case map[string]any:
+		pairs := make(map[string]object.Object, len(value))
+		for key, element := range value {
+			pairs[key] = i.fromJSON(element)
+		}
+		return &object.Hash{Pairs: pairs}
+	}
+	return &object.Error{Message: fmt.Sprintf("json_decode: unexpected %T", value)}
+}
+
+// fromNumber keeps a whole number too large for an Integer as a big
+// integer in big integer mode, and as a Float otherwise. A number too
+// large for a Float is an error.
+func (i *Interpreter) fromNumber(number json.Number) object.Object {
+	if !strings.ContainsAny(string(number), ".eE") {
+		if n, err := number.Int64(); err == nil {
+			return &object.Integer{Value: n}
+		}
+		if n, ok := new(big.Int).SetString(string(number), 10); ok && i.BigIntegers {
+			return &object.BigInteger{Value: n}
+		}
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return &object.Error{Message: fmt.Sprintf("json_decode: %s is out of range", number)}
+	}
+	return &object.Float{Value: f}
+}
+
+func jsonEncode(i *Interpreter, args []object.Object) object.Object {
+	out := &bytes.Buffer{}
+	if err := encode(out, args[0]); err != nil {
+		return &object.Error{Message: "json_encode: " + err.Error()}
+	}
+	return text(out.String())
+}
+
+func encode(out *bytes.Buffer, value object.Object) error {
+	switch value := value.(type) {
+	case *object.Null:
+		out.WriteString("null")
+	case *object.Boolean, *object.Integer, *object.BigInteger:
+		out.WriteString(value.Inspect())
+	case *object.Float:
+		if math.IsInf(value.Value, 0) || math.IsNaN(value.Value) {
+			return fmt.Errorf("%s has no JSON number", value.Inspect())
+		}
+		out.WriteString(value.Inspect())
+	case *object.String:
+		encodeString(out, value.Value)
+	case *object.Array:
+		out.WriteByte('[')
+		for n, element := range value.Elements {
+			if n > 0 {
+				out.WriteByte(',')
+			}
+			if err := encode(out, element); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case *object.Hash:
+		out.WriteByte('{')
+		for n, key := range value.Keys() {
+			if n > 0 {
+				out.WriteByte(',')
+			}
+			encodeString(out, key)
+			out.WriteByte(':')
+			if err := encode(out, value.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return fmt.Errorf("a %s has no JSON form", value.Type())
+	}
+	return nil
+}
+
+// encodeString writes s as a JSON string, leaving <, > and & as they
+// are.
+func encodeString(out *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	out.Truncate(out.Len() - 1) // the newline Encode ends with
+}
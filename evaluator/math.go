@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+// The math functions take Integers and Floats alike. The ones returning
+// an integer follow the mode of the interpreter, failing with an
+// overflow or growing into a big integer.
+
+// number returns an error naming the function when value is not a
+// number.
+func number(name string, value object.Object) *object.Error {
+	if !isNumber(value) {
+		return &object.Error{Message: fmt.Sprintf("%s: want a number, got %s", name, value.Type())}
+	}
+	return nil
+}
+
+// result returns n as an integer, failing when it does not fit in 64
+// bits and the interpreter is not in big integer mode.
+func (i *Interpreter) result(name string, n *big.Int) object.Object {
+	if !n.IsInt64() && !i.BigIntegers {
+		return &object.Error{Message: fmt.Sprintf("%s: %s", name, errOverflow)}
+	}
+	return bigResult(n)
+}
+
+func abs(i *Interpreter, args []object.Object) object.Object {
+	if err := number("abs", args[0]); err != nil {
+		return err
+	}
+	switch value := args[0].(type) {
+	case *object.Integer:
+		if value.Value >= 0 {
+			return value
+		}
+		return i.result("abs", new(big.Int).Neg(bigOf(value)))
+	case *object.BigInteger:
+		return bigResult(new(big.Int).Abs(value.Value))
+	}
+	return &object.Float{Value: math.Abs(toFloat(args[0]))}
+}
+
+func minimum(i *Interpreter, args []object.Object) object.Object {
+	return choose(i, "min", args, -1)
+}
+
+func maximum(i *Interpreter, args []object.Object) object.Object {
+	return choose(i, "max", args, 1)
+}
+
+// choose returns the argument that compares to the other as want, or the
+// first when they are equal.
+func choose(i *Interpreter, name string, args []object.Object, want int) object.Object {
+	for _, arg := range args {
+		if err := number(name, arg); err != nil {
+			return err
+		}
+	}
+	order, ok := compare(args[1], args[0])
+	if !ok {
+		return &object.Error{Message: fmt.Sprintf("%s: %s and %s are not ordered", name, args[0].Inspect(), args[1].Inspect())}
+	}
+	if order == want {
+		return args[1]
+	}
+	return args[0]
+}
+
+// maxBits bounds the integers pow builds, so a mistake in an exponent
+// fails instead of taking all the memory.
+const maxBits = 1 << 20
+
+func pow(i *Interpreter, args []object.Object) object.Object {
+	for _, arg := range args {
+		if err := number("pow", arg); err != nil {
+			return err
+		}
+	}
+	base, exponent := args[0], args[1]
+	if base.Type() == object.FLOAT_OBJ || exponent.Type() == object.FLOAT_OBJ {
+		return &object.Float{Value: math.Pow(toFloat(base), toFloat(exponent))}
+	}
+	n := bigOf(exponent)
+	if n.Sign() < 0 {
+		return &object.Error{Message: fmt.Sprintf("pow: exponent %s is negative, use a float base for fractions", n)}
+	}
+	b := bigOf(base)
+	if b.CmpAbs(big.NewInt(1)) > 0 {
+		if !n.IsInt64() || n.Int64() > maxBits/int64(b.BitLen()-1) {
+			return &object.Error{Message: fmt.Sprintf("pow: the result of %s to the power %s is too large", b, n)}
+		}
+	} else if !n.IsInt64() {
+		// 0, 1 and -1 stay small whatever the exponent, only its parity
+		// matters
+		n = new(big.Int).Add(new(big.Int).Rem(n, big.NewInt(2)), big.NewInt(2))
+	}
+	return i.result("pow", new(big.Int).Exp(b, n, nil))
+}
+
+func sqrt(i *Interpreter, args []object.Object) object.Object {
+	if err := number("sqrt", args[0]); err != nil {
+		return err
+	}
+	value := toFloat(args[0])
+	if value < 0 {
+		return &object.Error{Message: fmt.Sprintf("sqrt: %s is negative", args[0].Inspect())}
+	}
+	return &object.Float{Value: math.Sqrt(value)}
+}
+
+func floor(i *Interpreter, args []object.Object) object.Object {
+	return round(i, "floor", args[0], math.Floor)
+}
+
+func ceil(i *Interpreter, args []object.Object) object.Object {
+	return round(i, "ceil", args[0], math.Ceil)
+}
+
+// round turns a float into the integer f rounds it to, integers are
+// already whole and are returned as they are.
+func round(i *Interpreter, name string, value object.Object, f func(float64) float64) object.Object {
+	if err := number(name, value); err != nil {
+		return err
+	}
+	if value.Type() == object.INTEGER_OBJ {
+		return value
+	}
+	rounded := f(value.(*object.Float).Value)
+	if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+		return &object.Error{Message: fmt.Sprintf("%s: %s has no integer value", name, value.Inspect())}
+	}
+	n, _ := big.NewFloat(rounded).Int(nil)
+	return i.result(name, n)
+}
+
+func gcd(i *Interpreter, args []object.Object) object.Object {
+	a := new(big.Int).Abs(bigOf(args[0]))
+	b := new(big.Int).Abs(bigOf(args[1]))
+	return i.result("gcd", new(big.Int).GCD(nil, nil, a, b))
+}
+
+// integerRange is the numbers an ovvonrum loop counts from the first
+// argument up to the second, empty when the second is not above it.
+func integerRange(i *Interpreter, args []object.Object) object.Object {
+	return &object.Range{Start: integer(args[0]), End: integer(args[1])}
+}
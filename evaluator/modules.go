@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// module is a file run by an import. Each module runs once, in its own
+// environment, and every later import of it gets the same names.
+type module struct {
+	path string // as it was resolved, for messages
+	env  *object.Environment
+	done bool // false while the module runs, importing it then is a cycle
+}
+
+// Exported reports whether a top level name of a module is seen by the
+// files importing it, names starting with _ are kept to the module.
+func Exported(name string) bool {
+	return !strings.HasPrefix(name, "_")
+}
+
+// Resolve returns the path of the file imported as name by the file at
+// from. An empty from resolves against the current directory.
+func Resolve(from, name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	dir := "."
+	if from != "" {
+		dir = filepath.Dir(from)
+	}
+	return filepath.Join(dir, name)
+}
+
+func (i *Interpreter) evalImport(stmt *tree.ImportStmt, env *object.Environment) {
+	m := i.load(stmt.Path.Value)
+	for _, name := range m.env.Names() {
+		if Exported(name) {
+			value, _ := m.env.Get(name)
+			env.Set(name, value)
+		}
+	}
+}
+
+// load runs the module imported as name from the file running now, or
+// returns it when it already ran.
+func (i *Interpreter) load(name string) *module {
+	if i.modules == nil {
+		i.modules = map[string]*module{}
+		// the program itself can not be imported by the modules it imports
+		if key, err := filepath.Abs(i.Path); i.Path != "" && err == nil {
+			i.modules[key] = &module{path: i.Path}
+		}
+	}
+	from := i.Path
+	if n := len(i.importing); n > 0 {
+		from = i.importing[n-1].path
+	}
+	path := Resolve(from, name)
+	key, err := filepath.Abs(path)
+	if err != nil {
+		i.fatal(fmt.Sprintf("Cannot import %s: %s", path, err))
+	}
+	if m, ok := i.modules[key]; ok {
+		if !m.done {
+			i.fatal("Import cycle: " + i.cycle(path))
+		}
+		return m
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		i.fatal(fmt.Sprintf("Cannot import %s: %s", path, err))
+	}
+	_, channel := lexer.CreateLexer(source, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		i.fatal(fmt.Sprintf("%s:%s", path, err))
+	}
+
+	m := &module{path: path, env: object.NewEnvironment()}
+	i.modules[key] = m
+	defer func() {
+		// a module stopped by an error, caught by a muyarchi, runs again
+		// when it is imported again instead of looking like a cycle
+		if !m.done {
+			delete(i.modules, key)
+		}
+	}()
+	i.run(m, program)
+	m.done = true
+	return m
+}
+
+// run evaluates the program of a module. Hooks only watch the statements
+// of the main program, an error in the module is reported at the import
+// of the main program that led to it.
+func (i *Interpreter) run(m *module, program *tree.Program) {
+	hook, depth, current := i.Hook, i.depth, i.current
+	i.Hook, i.depth, i.current = nil, 0, nil
+	i.importing = append(i.importing, m)
+	defer func() {
+		i.Hook, i.depth, i.current = hook, depth, current
+		i.importing = i.importing[:len(i.importing)-1]
+		r := recover()
+		if runtime, ok := r.(*RuntimeError); ok && len(i.importing) == 0 && i.current != nil {
+			runtime.Pos = tree.SpanOf(i.current).Start
+			if i.Hook != nil {
+				i.Hook.OnError(i.current, &object.Error{Message: runtime.Message})
+			}
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+	i.eval(program, m.env)
+}
+
+// locate prefixes the message of an error in a module with the place it
+// happened.
+func (i *Interpreter) locate(message string, pos lexer.Position) string {
+	n := len(i.importing)
+	if n == 0 {
+		return message
+	}
+	if !pos.IsValid() {
+		return fmt.Sprintf("%s: %s", i.importing[n-1].path, message)
+	}
+	return fmt.Sprintf("%s:%s: %s", i.importing[n-1].path, pos, message)
+}
+
+// cycle lists the files from the program to the one imported again.
+func (i *Interpreter) cycle(path string) string {
+	files := []string{}
+	if i.Path != "" {
+		files = append(files, i.Path)
+	}
+	for _, m := range i.importing {
+		files = append(files, m.path)
+	}
+	return strings.Join(append(files, path), " -> ")
+}
@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+// modules writes the files under a new directory and returns it.
+func modules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImport(t *testing.T) {
+	dir := modules(t, map[string]string{
+		"main.n": `"lib/greet.n" irakkumathi; "lib/greet.n" இறக்குமதி; greeting sollu; count sollu;`,
+		// runs once even when imported twice, and finds name.n next to it
+		"lib/greet.n": `"name.n" irakkumathi; "loading" sollu; sol greeting = "vanakkam " + name; yen _hidden = 1;`,
+		"lib/name.n":  `sol name = "nila"; yen count = 2;`,
+	})
+	out := &bytes.Buffer{}
+	path := filepath.Join(dir, "main.n")
+	source, _ := os.ReadFile(path)
+	env := object.NewEnvironment()
+	interpreter := &Interpreter{Out: out, Path: path}
+	if err := interpreter.Run(parse(string(source)), env); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "loading\nvanakkam nila\n2\n" {
+		t.Errorf("got %q", out.String())
+	}
+	if _, ok := env.Get("_hidden"); ok {
+		t.Error("_hidden should stay in its module")
+	}
+}
+
+func TestImportFailingTwice(t *testing.T) {
+	dir := modules(t, map[string]string{
+		"main.n": `muyarchi { "bad.n" irakkumathi; } pidi e { e["message"] sollu; }` + "\n" +
+			`muyarchi { "bad.n" irakkumathi; } pidi e { e["message"], e["line"] sollu; }`,
+		"bad.n": `"loading" sollu; "broken" eri;`,
+	})
+	out := &bytes.Buffer{}
+	path := filepath.Join(dir, "main.n")
+	source, _ := os.ReadFile(path)
+	interpreter := &Interpreter{Out: out, Path: path}
+	if err := interpreter.Run(parse(string(source)), object.NewEnvironment()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "loading\nDIR/bad.n:1:18: broken\nloading\nDIR/bad.n:1:18: broken 2\n"
+	if got := strings.ReplaceAll(filepath.ToSlash(out.String()), filepath.ToSlash(dir), "DIR"); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := modules(t, map[string]string{
+		"cycle.n":  `"a.n" irakkumathi;`,
+		"a.n":      `"b.n" irakkumathi;`,
+		"b.n":      `"cycle.n" irakkumathi;`,
+		"fail.n":   `yen a = 1;` + "\n" + `"bad.n" irakkumathi;`,
+		"bad.n":    `yen x = 1;` + "\n" + `yen y = "x";`,
+		"syntax.n": `"broken.n" irakkumathi;`,
+		"broken.n": `yen = 1;`,
+		"deep.n":   `"a/b.n" irakkumathi;`,
+		"a/b.n":    `"missing.n" irakkumathi;`,
+	})
+	tests := []struct {
+		file string
+		err  string
+	}{
+		{"cycle.n", "DIR/b.n:1:1: Import cycle: DIR/cycle.n -> DIR/a.n -> DIR/b.n -> DIR/cycle.n"},
+		{"fail.n", "DIR/bad.n:2:1: Cannot Assign STRING to INTEGER variable"},
+		{"syntax.n", "DIR/broken.n:1:5: Expected identifier got ="},
+		{"deep.n", "DIR/a/b.n:1:1: Cannot import DIR/a/missing.n: no such file or directory"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		source, _ := os.ReadFile(path)
+		interpreter := &Interpreter{Out: &bytes.Buffer{}, Path: path}
+		err := interpreter.Run(parse(string(source)), object.NewEnvironment())
+		runtime, ok := err.(*RuntimeError)
+		if !ok {
+			t.Errorf("%s: got %v, want %s", tt.file, err, tt.err)
+			continue
+		}
+		message := strings.ReplaceAll(filepath.ToSlash(runtime.Message), filepath.ToSlash(dir), "DIR")
+		if message != tt.err {
+			t.Errorf("%s: got %s, want %s", tt.file, message, tt.err)
+		}
+		// the position is the import of the program that led to the error
+		if source := strings.Split(string(source), "\n"); runtime.Pos.Line != len(source) {
+			t.Errorf("%s: the error is at %s", tt.file, runtime.Pos)
+		}
+	}
+}
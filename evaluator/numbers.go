@@ -0,0 +1,228 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+)
+
+// Integers are 64 bits wide and an operation whose result does not fit
+// fails with an overflow, unless the interpreter runs in big integer
+// mode, where the result grows into a BigInteger. A BigInteger that
+// shrinks back into 64 bits becomes an Integer again, so the two are the
+// same type to a program. An operation with a Float is done in floats.
+
+var (
+	errOverflow = errors.New("Integer overflow")
+	errDivision = errors.New("Division by zero")
+)
+
+func isNumber(value object.Object) bool {
+	switch value.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float:
+		return true
+	}
+	return false
+}
+
+// arithmetic applies - * / or %, and + on numbers.
+func arithmetic(operator lexer.PieceType, left, right object.Object, promote bool) object.Object {
+	if !isNumber(left) || !isNumber(right) {
+		return operandError(operator, left, right)
+	}
+	if left.Type() == object.FLOAT_OBJ || right.Type() == object.FLOAT_OBJ {
+		return floatArithmetic(operator, toFloat(left), toFloat(right))
+	}
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		value, err := intArithmetic(operator, l.Value, r.Value)
+		switch {
+		case err == nil:
+			return &object.Integer{Value: value}
+		case err == errOverflow && !promote:
+			return &object.Error{Message: fmt.Sprintf("%s: %d %s %d", err, l.Value, symbol(operator), r.Value)}
+		case err != errOverflow:
+			return &object.Error{Message: err.Error()}
+		}
+	}
+	return bigArithmetic(operator, bigOf(left), bigOf(right))
+}
+
+func operandError(operator lexer.PieceType, left, right object.Object) object.Object {
+	if operator == lexer.Plus {
+		return &object.Error{Message: "Unknown Type"}
+	}
+	if left.Type() != right.Type() {
+		return &object.Error{Message: fmt.Sprintf("Type Mismatch: Cannot perform operation with %s and %s", left.Type(), right.Type())}
+	}
+	if left.Type() == object.STRING_OBJ {
+		switch operator {
+		case lexer.Minus:
+			return &object.Error{Message: "Cannot Subtract Strings"}
+		case lexer.Star:
+			return &object.Error{Message: "Cannot Multiply Strings"}
+		default:
+			return &object.Error{Message: "Cannot Divide Strings"}
+		}
+	}
+	return &object.Error{Message: "Unknown Type"}
+}
+
+func symbol(operator lexer.PieceType) string {
+	switch operator {
+	case lexer.Plus:
+		return "+"
+	case lexer.Minus:
+		return "-"
+	case lexer.Star:
+		return "*"
+	case lexer.Slash:
+		return "/"
+	}
+	return "%"
+}
+
+// intArithmetic fails with errOverflow when the result does not fit.
+func intArithmetic(operator lexer.PieceType, a, b int64) (int64, error) {
+	switch operator {
+	case lexer.Plus:
+		c := a + b
+		if (b > 0 && c < a) || (b < 0 && c > a) {
+			return 0, errOverflow
+		}
+		return c, nil
+	case lexer.Minus:
+		c := a - b
+		if (b > 0 && c > a) || (b < 0 && c < a) {
+			return 0, errOverflow
+		}
+		return c, nil
+	case lexer.Star:
+		if a == 0 || b == 0 {
+			return 0, nil
+		}
+		c := a * b
+		if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+			return 0, errOverflow
+		}
+		return c, nil
+	case lexer.Slash:
+		if b == 0 {
+			return 0, errDivision
+		}
+		if a == math.MinInt64 && b == -1 {
+			return 0, errOverflow
+		}
+		return a / b, nil
+	default:
+		if b == 0 {
+			return 0, errDivision
+		}
+		return a % b, nil
+	}
+}
+
+func bigArithmetic(operator lexer.PieceType, a, b *big.Int) object.Object {
+	c := new(big.Int)
+	switch operator {
+	case lexer.Plus:
+		c.Add(a, b)
+	case lexer.Minus:
+		c.Sub(a, b)
+	case lexer.Star:
+		c.Mul(a, b)
+	default:
+		if b.Sign() == 0 {
+			return &object.Error{Message: errDivision.Error()}
+		}
+		// Quo and Rem truncate like the operators on int64 do
+		if operator == lexer.Slash {
+			c.Quo(a, b)
+		} else {
+			c.Rem(a, b)
+		}
+	}
+	return bigResult(c)
+}
+
+func floatArithmetic(operator lexer.PieceType, a, b float64) object.Object {
+	switch operator {
+	case lexer.Plus:
+		return &object.Float{Value: a + b}
+	case lexer.Minus:
+		return &object.Float{Value: a - b}
+	case lexer.Star:
+		return &object.Float{Value: a * b}
+	}
+	if b == 0 {
+		return &object.Error{Message: errDivision.Error()}
+	}
+	if operator == lexer.Slash {
+		return &object.Float{Value: a / b}
+	}
+	return &object.Float{Value: math.Mod(a, b)}
+}
+
+// compare orders two numbers, ok is false when either is not a number.
+func compare(left, right object.Object) (order int, ok bool) {
+	if !isNumber(left) || !isNumber(right) {
+		return 0, false
+	}
+	if left.Type() == object.FLOAT_OBJ || right.Type() == object.FLOAT_OBJ {
+		a, b := toFloat(left), toFloat(right)
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		case a == b:
+			return 0, true
+		}
+		// NaN is not ordered
+		return 0, false
+	}
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		switch {
+		case l.Value < r.Value:
+			return -1, true
+		case l.Value > r.Value:
+			return 1, true
+		}
+		return 0, true
+	}
+	return bigOf(left).Cmp(bigOf(right)), true
+}
+
+// bigOf returns an integer as a big.Int the caller may not change.
+func bigOf(value object.Object) *big.Int {
+	if value, ok := value.(*object.BigInteger); ok {
+		return value.Value
+	}
+	return big.NewInt(value.(*object.Integer).Value)
+}
+
+func toFloat(value object.Object) float64 {
+	switch value := value.(type) {
+	case *object.Integer:
+		return float64(value.Value)
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(value.Value).Float64()
+		return f
+	}
+	return value.(*object.Float).Value
+}
+
+// bigResult returns n as an Integer when it fits in one.
+func bigResult(n *big.Int) object.Object {
+	if n.IsInt64() {
+		return &object.Integer{Value: n.Int64()}
+	}
+	return &object.BigInteger{Value: n}
+}
@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`7 / 2`, "3"},
+		{`0 + -7 % 3`, "-1"},
+		{`7.0 / 2`, "3.5"},
+		{`1 + 0.5`, "1.5"},
+		{`0.1 + 0.2`, "0.30000000000000004"},
+		{`2.5 * 2`, "5.0"},
+		{`7.5 % 2`, "1.5"},
+		{`2 * -1.5`, "-3.0"},
+		{`"pi " + 3.14`, "pi 3.14"},
+		{`1 < 1.5`, "true"},
+		{`2 == 2.0`, "true"},
+		{`2.0 != 2`, "false"},
+		{`1 / 0`, "ERROR: Division by zero"},
+		{`1 % 0`, "ERROR: Division by zero"},
+		{`1.0 / 0`, "ERROR: Division by zero"},
+		{`9223372036854775807 + 1`, "ERROR: Integer overflow: 9223372036854775807 + 1"},
+		{`0 + -9223372036854775807 - 2`, "ERROR: Integer overflow: -9223372036854775807 - 2"},
+		{`4611686018427387904 * 2`, "ERROR: Integer overflow: 4611686018427387904 * 2"},
+		{`1 * (-9223372036854775807 - 1) / -1`, "ERROR: Integer overflow: -9223372036854775808 / -1"},
+		{`0 + -(-9223372036854775807 - 1)`, "ERROR: Integer overflow: -(-9223372036854775808)"},
+		{`"a" < 1`, "ERROR: Invalid Operand Types STRING and INTEGER for <"},
+		{`1 && aam`, "ERROR: Invalid Operand Types INTEGER and BOOLEAN for &&"},
+	}
+	for _, tt := range tests {
+		if got := evalPrint(tt.expr); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`yen f = 1; yen n = 1; 25 murai { f = f * n; n = n + 1; } f sollu;`, "15511210043330985984000000"},
+		// a result back in range is an Integer again
+		{`yen a = 9223372036854775807 + 1; a - 1 sollu; a > 1 sollu; a == a sollu;`, "9223372036854775807\ntrue\ntrue"},
+		{`0 + -(-9223372036854775807 - 1) sollu;`, "9223372036854775808"},
+		{`yen a = 9223372036854775807 + 2; a / 2 sollu; a % 2 sollu;`, "4611686018427387904\n1"},
+		{`pow(2, 100) sollu; abs(-pow(3, 40)) sollu;`, "1267650600228229401496703205376\n12157665459056928801"},
+		{`gcd(pow(2, 70), pow(6, 40)) sollu;`, "1099511627776"},
+		{`0.5 * (9223372036854775807 + 1) sollu;`, "4611686018427388000.0"},
+		{`"x"[9223372036854775807 + 1] sollu;`, "ERROR: Index out of range"},
+		{`repeat("x", 9223372036854775807 + 1) sollu;`, "ERROR: Argument 2 of repeat is too large"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		interpreter := &Interpreter{Out: out, BigIntegers: true}
+		if err := interpreter.Run(parse(tt.input), object.NewEnvironment()); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if got := strings.TrimSuffix(out.String(), "\n"); got != tt.expected {
+			t.Errorf("%s\ngot  %s\nwant %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// an overflow stored in a variable stops the program, when it is
+// declared and when it is assigned again
+func TestOverflowStops(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`yen a = 4611686018427387904 * 2; "never" sollu;`, "ERROR: Integer overflow: 4611686018427387904 * 2"},
+		{`yen a = 4611686018427387904; a = a * 2; "never" sollu;`, "ERROR: Integer overflow: 4611686018427387904 * 2"},
+		{`yen a = 1; 70 murai { a = a * 2; } "never" sollu;`, "ERROR: Integer overflow: 4611686018427387904 * 2"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		err := (&Interpreter{Out: out}).Run(parse(tt.input), object.NewEnvironment())
+		if err == nil || err.Error() != tt.err || out.Len() != 0 {
+			t.Errorf("%s: got %v and %q, want %s", tt.input, err, out.String(), tt.err)
+		}
+	}
+}
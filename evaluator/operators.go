@@ -0,0 +1,180 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/iam-naveen/compiler/grapheme"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+)
+
+// The operator semantics are kept apart from the tree walk so that other
+// backends (the bytecode vm) behave exactly like the evaluator.
+
+func EvalIndex(left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.String:
+		i, ok := position(index)
+		if !ok {
+			return &object.Error{Message: "Index must be an Integer"}
+		}
+		letters := grapheme.Split(left.Value)
+		if i < 0 || i >= int64(len(letters)) {
+			return &object.Error{Message: "Index out of range"}
+		}
+		return &object.String{Value: letters[i]}
+	case *object.Array:
+		i, ok := position(index)
+		if !ok {
+			return &object.Error{Message: "Index must be an Integer"}
+		}
+		if i < 0 || i >= int64(len(left.Elements)) {
+			return &object.Error{Message: "Index out of range"}
+		}
+		return left.Elements[i]
+	case *object.Hash:
+		key, ok := index.(*object.String)
+		if !ok {
+			return &object.Error{Message: "Key must be a String"}
+		}
+		value, ok := left.Pairs[key.Value]
+		if !ok {
+			return &object.Error{Message: fmt.Sprintf("Key %q not found", key.Value)}
+		}
+		return value
+	}
+	return &object.Error{Message: "Unknown expression"}
+}
+
+// position returns an index as an int64, a big integer is past the end
+// of anything and becomes -1.
+func position(index object.Object) (int64, bool) {
+	switch index := index.(type) {
+	case *object.Integer:
+		return index.Value, true
+	case *object.BigInteger:
+		return -1, true
+	}
+	return 0, false
+}
+
+func EvalLength(value object.Object) object.Object {
+	switch value := value.(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(grapheme.Count(value.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(value.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(value.Pairs))}
+	default:
+		return &object.Error{Message: "Length can only be applied to Strings, Arrays and Hashes"}
+	}
+}
+
+func EvalPrefix(operator lexer.Piece, right object.Object) object.Object {
+	return evalPrefix(operator, right, false)
+}
+
+// evalPrefix is EvalPrefix for an interpreter, promote lets a negated
+// integer grow into a big integer instead of overflowing.
+func evalPrefix(operator lexer.Piece, right object.Object, promote bool) object.Object {
+	if err, ok := right.(*object.Error); ok {
+		return err
+	}
+	switch operator.Kind {
+	case lexer.Minus:
+		switch right := right.(type) {
+		case *object.Integer:
+			if right.Value == math.MinInt64 {
+				if promote {
+					return bigResult(new(big.Int).Neg(bigOf(right)))
+				}
+				return &object.Error{Message: fmt.Sprintf("Integer overflow: -(%d)", right.Value)}
+			}
+			return &object.Integer{Value: -right.Value}
+		case *object.BigInteger:
+			return bigResult(new(big.Int).Neg(right.Value))
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		}
+		return &object.Error{Message: "Invalid Operand Type"}
+	case lexer.Plus:
+		if !isNumber(right) {
+			return &object.Error{Message: "Invalid Operand Type"}
+		}
+		return right
+	case lexer.Bang:
+		if right.Type() != object.BOOLEAN_OBJ {
+			return &object.Error{Message: "Invalid Operand Type"}
+		}
+		return &object.Boolean{Value: !right.(*object.Boolean).Value}
+	}
+	return &object.Error{Message: "Unknown expression"}
+}
+
+func EvalBinary(operator lexer.Piece, left, right object.Object) object.Object {
+	return evalBinary(operator, left, right, false)
+}
+
+// evalBinary is EvalBinary for an interpreter, promote lets integer
+// arithmetic grow into big integers instead of overflowing.
+func evalBinary(operator lexer.Piece, left, right object.Object, promote bool) object.Object {
+	if err, ok := left.(*object.Error); ok {
+		return err
+	}
+	if err, ok := right.(*object.Error); ok {
+		return err
+	}
+	switch operator.Kind {
+	case lexer.Plus:
+		return evaluatePlus(left, right, promote)
+	case lexer.Minus, lexer.Star, lexer.Slash, lexer.Percent:
+		return arithmetic(operator.Kind, left, right, promote)
+	case lexer.Equal:
+		return &object.Boolean{Value: equal(left, right)}
+	case lexer.NotEqual:
+		return &object.Boolean{Value: !equal(left, right)}
+	case lexer.Less, lexer.Greater, lexer.LessEqual, lexer.GreaterEqual:
+		order, ok := compare(left, right)
+		if !ok {
+			return &object.Error{Message: fmt.Sprintf("Invalid Operand Types %s and %s for %s", left.Type(), right.Type(), operator.Value)}
+		}
+		switch operator.Kind {
+		case lexer.Less:
+			return &object.Boolean{Value: order < 0}
+		case lexer.Greater:
+			return &object.Boolean{Value: order > 0}
+		case lexer.LessEqual:
+			return &object.Boolean{Value: order <= 0}
+		}
+		return &object.Boolean{Value: order >= 0}
+	case lexer.And, lexer.Or:
+		l, lok := left.(*object.Boolean)
+		r, rok := right.(*object.Boolean)
+		if !lok || !rok {
+			return &object.Error{Message: fmt.Sprintf("Invalid Operand Types %s and %s for %s", left.Type(), right.Type(), operator.Value)}
+		}
+		if operator.Kind == lexer.And {
+			return &object.Boolean{Value: l.Value && r.Value}
+		}
+		return &object.Boolean{Value: l.Value || r.Value}
+	default:
+		msg := fmt.Sprintf(
+			"Unknown Operator '%s' for %s",
+			operator.Value,
+			left.Type(),
+		)
+		return &object.Error{Message: msg}
+	}
+}
+
+// equal compares numbers by value, so 2 == 2.0, and other values by how
+// they are written.
+func equal(left, right object.Object) bool {
+	if order, ok := compare(left, right); ok {
+		return order == 0
+	}
+	return left.Inspect() == right.Inspect()
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/iam-naveen/compiler/object"
+)
+
+// random returns the generator of the interpreter, seeding one from the
+// time when it has none.
+func (i *Interpreter) random() *rand.Rand {
+	if i.Rand == nil {
+		i.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return i.Rand
+}
+
+// randomInt returns an integer from low up to and including high.
+func randomInt(i *Interpreter, args []object.Object) object.Object {
+	low, high := integer(args[0]), integer(args[1])
+	if low > high {
+		return &object.Error{Message: fmt.Sprintf("random_int: low %d is above high %d", low, high)}
+	}
+	// the count of values may not fit in an int64, like for the whole range
+	count := new(big.Int).Sub(big.NewInt(high), big.NewInt(low))
+	count.Add(count, big.NewInt(1))
+	n := new(big.Int).Rand(i.random(), count)
+	return &object.Integer{Value: n.Add(n, big.NewInt(low)).Int64()}
+}
+
+func randomChoice(i *Interpreter, args []object.Object) object.Object {
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return &object.Error{Message: "random_choice: the array is empty"}
+	}
+	return elements[i.random().Intn(len(elements))]
+}
+
+// shuffle returns the elements in a random order, the array given is not
+// changed.
+func shuffle(i *Interpreter, args []object.Object) object.Object {
+	elements := append([]object.Object{}, args[0].(*object.Array).Elements...)
+	i.random().Shuffle(len(elements), func(a, b int) {
+		elements[a], elements[b] = elements[b], elements[a]
+	})
+	return &object.Array{Elements: elements}
+}
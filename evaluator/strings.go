@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/grapheme"
+	"github.com/iam-naveen/compiler/object"
+)
+
+// The string functions count positions in letters, like indexing does,
+// and never change the strings they are given.
+
+func str(value object.Object) string {
+	return value.(*object.String).Value
+}
+
+func integer(value object.Object) int64 {
+	return value.(*object.Integer).Value
+}
+
+func text(s string) object.Object {
+	return &object.String{Value: s}
+}
+
+// letters splits s into the units indexing counts, a Tamil letter with
+// its vowel sign is one of them.
+func letters(s string) []string {
+	return grapheme.Split(s)
+}
+
+func split(i *Interpreter, args []object.Object) object.Object {
+	s, sep := str(args[0]), str(args[1])
+	parts := letters(s)
+	if sep != "" {
+		parts = strings.Split(s, sep)
+	}
+	elements := make([]object.Object, len(parts))
+	for n, part := range parts {
+		elements[n] = text(part)
+	}
+	return &object.Array{Elements: elements}
+}
+
+func join(i *Interpreter, args []object.Object) object.Object {
+	parts := []string{}
+	for _, element := range args[0].(*object.Array).Elements {
+		parts = append(parts, element.Inspect())
+	}
+	return text(strings.Join(parts, str(args[1])))
+}
+
+func trim(i *Interpreter, args []object.Object) object.Object {
+	return text(strings.TrimSpace(str(args[0])))
+}
+
+func upper(i *Interpreter, args []object.Object) object.Object {
+	return text(strings.ToUpper(str(args[0])))
+}
+
+func lower(i *Interpreter, args []object.Object) object.Object {
+	return text(strings.ToLower(str(args[0])))
+}
+
+func contains(i *Interpreter, args []object.Object) object.Object {
+	return &object.Boolean{Value: strings.Contains(str(args[0]), str(args[1]))}
+}
+
+// indexOf returns the letter the first match starts at, -1 when there is
+// none.
+func indexOf(i *Interpreter, args []object.Object) object.Object {
+	s, sub := str(args[0]), str(args[1])
+	at := strings.Index(s, sub)
+	if at < 0 {
+		return &object.Integer{Value: -1}
+	}
+	return &object.Integer{Value: int64(len(letters(s[:at])))}
+}
+
+func replace(i *Interpreter, args []object.Object) object.Object {
+	return text(strings.ReplaceAll(str(args[0]), str(args[1]), str(args[2])))
+}
+
+func repeat(i *Interpreter, args []object.Object) object.Object {
+	s, count := str(args[0]), integer(args[1])
+	if count < 0 {
+		return &object.Error{Message: fmt.Sprintf("repeat: count %d is negative", count)}
+	}
+	if count > 0 && int64(len(s)) > maxString/count {
+		return &object.Error{Message: fmt.Sprintf("repeat: the result of %d copies is too long", count)}
+	}
+	return text(strings.Repeat(s, int(count)))
+}
+
+// maxString bounds the strings repeat builds, so a mistake in a count
+// fails instead of taking all the memory.
+const maxString = 1 << 28
+
+// substring returns the letters from start up to, not including, end.
+func substring(i *Interpreter, args []object.Object) object.Object {
+	parts := letters(str(args[0]))
+	start, end := integer(args[1]), integer(args[2])
+	if start < 0 || end > int64(len(parts)) || start > end {
+		return &object.Error{Message: fmt.Sprintf("substring: %d to %d is out of range for %d letters", start, end, len(parts))}
+	}
+	return text(strings.Join(parts[start:end], ""))
+}
+
+func parseInt(i *Interpreter, args []object.Object) object.Object {
+	s := str(args[0])
+	value, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return &object.Error{Message: fmt.Sprintf("parse_int: %q is out of range", s)}
+	}
+	if err != nil {
+		return &object.Error{Message: fmt.Sprintf("parse_int: %q is not an integer", s)}
+	}
+	return &object.Integer{Value: value}
+}
+
+func runeCount(i *Interpreter, args []object.Object) object.Object {
+	return &object.Integer{Value: int64(utf8.RuneCountInString(str(args[0])))}
+}
+
+func byteCount(i *Interpreter, args []object.Object) object.Object {
+	return &object.Integer{Value: int64(len(str(args[0])))}
+}
+
+func formatInt(i *Interpreter, args []object.Object) object.Object {
+	return text(strconv.FormatInt(integer(args[0]), 10))
+}
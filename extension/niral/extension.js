@@ -0,0 +1,26 @@
+const vscode = require('vscode');
+const { LanguageClient } = require('vscode-languageclient/node');
+
+let client;
+
+// activate starts `niral lsp` for the open .n files and `niral dap` for
+// each debug session
+function activate(context) {
+  const command = vscode.workspace.getConfiguration('niral').get('server.path', 'niral');
+  context.subscriptions.push(vscode.debug.registerDebugAdapterDescriptorFactory('niral', {
+    createDebugAdapterDescriptor: () => new vscode.DebugAdapterExecutable(command, ['dap'])
+  }));
+  client = new LanguageClient(
+    'niral',
+    'Niral Language Server',
+    { command, args: ['lsp'] },
+    { documentSelector: [{ scheme: 'file', language: 'niral' }] }
+  );
+  context.subscriptions.push(client.start());
+}
+
+function deactivate() {
+  return client ? client.stop() : undefined;
+}
+
+module.exports = { activate, deactivate };
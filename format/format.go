@@ -0,0 +1,337 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+const indent = "    "
+
+// Source parses src and returns it in the canonical style.
+func Source(src []byte) ([]byte, error) {
+	_, channel := lexer.CreateLexer(src, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		return nil, err
+	}
+	return Program(program)
+}
+
+// Program renders the program as source, one statement per line, with
+// the comments of the program placed back where they were found.
+func Program(program *tree.Program) ([]byte, error) {
+	p := &printer{comments: program.Comments}
+	if err := p.statements(program.Statements); err != nil {
+		return nil, err
+	}
+	p.flush(-1)
+	return p.out.Bytes(), nil
+}
+
+type printer struct {
+	out      bytes.Buffer
+	level    int
+	comments []*tree.Comment // not printed yet
+	lastLine int             // source line of the last thing printed
+	open     bool            // nothing printed yet in the current block
+}
+
+func (p *printer) statements(stmts []tree.Stmt) error {
+	p.open = true
+	for _, stmt := range stmts {
+		span := tree.SpanOf(stmt)
+		p.flush(span.Start.Offset)
+		p.separate(span.Start.Line)
+		p.write(strings.Repeat(indent, p.level))
+		if err := p.statement(stmt); err != nil {
+			return err
+		}
+		p.trailing(span.End.Line)
+		p.out.WriteString("\n")
+		p.open = false
+	}
+	return nil
+}
+
+// flush prints the comments found before offset on lines of their own,
+// or all remaining comments when offset is negative. Comments without
+// a position are kept for the end.
+func (p *printer) flush(offset int) {
+	for len(p.comments) > 0 {
+		comment := p.comments[0]
+		pos := comment.Piece.Pos
+		if offset >= 0 && (!pos.IsValid() || pos.Offset >= offset) {
+			return
+		}
+		p.separate(pos.Line)
+		p.write(strings.Repeat(indent, p.level) + comment.Text + "\n")
+		p.lastLine = pos.Line
+		p.open = false
+		p.comments = p.comments[1:]
+	}
+}
+
+// trailing appends a comment found on the line the statement ended.
+func (p *printer) trailing(line int) {
+	if len(p.comments) > 0 && line > 0 && p.comments[0].Piece.Pos.Line == line {
+		p.write(" " + p.comments[0].Text)
+		p.comments = p.comments[1:]
+	}
+	if line > 0 {
+		p.lastLine = line
+	}
+}
+
+// separate keeps a single blank line where the source had one or more.
+func (p *printer) separate(line int) {
+	if !p.open && p.lastLine > 0 && line > p.lastLine+1 {
+		p.out.WriteString("\n")
+	}
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+func (p *printer) block(block *tree.Block) error {
+	p.write("{")
+	if len(block.Statements) == 0 && !p.commentsBefore(block.Close) {
+		p.write("}")
+		return nil
+	}
+	p.write("\n")
+	p.level++
+	p.lastLine = block.Piece.Pos.Line
+	if err := p.statements(block.Statements); err != nil {
+		return err
+	}
+	if block.Close.Pos.IsValid() {
+		p.flush(block.Close.Pos.Offset)
+	}
+	p.level--
+	p.write(strings.Repeat(indent, p.level) + "}")
+	return nil
+}
+
+func (p *printer) commentsBefore(piece lexer.Piece) bool {
+	return len(p.comments) > 0 && piece.Pos.IsValid() &&
+		p.comments[0].Piece.Pos.IsValid() && p.comments[0].Piece.Pos.Offset < piece.Pos.Offset
+}
+
+func (p *printer) statement(stmt tree.Stmt) error {
+	switch stmt := stmt.(type) {
+	case *tree.Declaration:
+		p.write(keyword(stmt.Piece, datatypeKeyword(stmt.Datatype)) + " " + stmt.Name.Value)
+		if stmt.Value != nil {
+			p.write(" = " + expression(stmt.Value, ASSIGNMENT))
+		}
+		p.write(";")
+	case *tree.Input:
+		p.write(datatypeKeyword(stmt.DataType) + " " + stmt.Variable.Name)
+		if stmt.Prompt != nil {
+			p.write(" = " + expression(stmt.Prompt, ASSIGNMENT))
+		}
+		p.write(" " + keyword(stmt.Piece, "kodu") + ";")
+	case *tree.ExpressionStmt:
+		p.write(expression(stmt.Expression, LOWEST) + ";")
+	case *tree.PrintStmt:
+		values := []string{}
+		for _, value := range stmt.Values {
+			values = append(values, expression(value, LOWEST))
+		}
+		p.write(strings.Join(values, ", ") + " " + keyword(stmt.Piece, "sollu") + ";")
+	case *tree.ImportStmt:
+		p.write(expression(&stmt.Path, LOWEST) + " " + keyword(stmt.Piece, "irakkumathi") + ";")
+	case *tree.BranchStmt:
+		p.write(stmt.Piece.Value + ";")
+	case *tree.RaiseStmt:
+		p.write(expression(stmt.Value, LOWEST) + " " + keyword(stmt.Piece, "eri") + ";")
+	case *tree.TryStmt:
+		p.write(keyword(stmt.Piece, "muyarchi") + " ")
+		if err := p.block(stmt.Body); err != nil {
+			return err
+		}
+		p.write(" " + keyword(stmt.Catch, "pidi") + " " + stmt.Name.Name + " ")
+		if err := p.block(stmt.Handler); err != nil {
+			return err
+		}
+		if stmt.Finally != nil {
+			p.write(" iruthiyaga ")
+			return p.block(stmt.Finally)
+		}
+	case *tree.IfStmt:
+		return p.ifStatement(stmt)
+	case *tree.WhileStmt:
+		p.write(expression(stmt.Condition, LOWEST) + " " + keyword(stmt.Piece, "varaikkum") + " ")
+		return p.block(stmt.Body)
+	case *tree.ForStmt:
+		p.write(expression(stmt.Count, LOWEST) + " " + keyword(stmt.Piece, "murai") + " ")
+		return p.block(stmt.Body)
+	case *tree.EachStmt:
+		p.write(expression(stmt.Iterable, LOWEST) + " " + keyword(stmt.Piece, "ovvonrum") + " ")
+		if stmt.Index != nil {
+			p.write(stmt.Index.Name + ", ")
+		}
+		p.write(stmt.Element.Name + " ")
+		return p.block(stmt.Body)
+	default:
+		return fmt.Errorf("cannot format %T", stmt)
+	}
+	return nil
+}
+
+func (p *printer) ifStatement(stmt *tree.IfStmt) error {
+	p.write(expression(stmt.Condition, LOWEST) + " " + keyword(stmt.Piece, "endral") + " ")
+	if err := p.block(stmt.Then); err != nil {
+		return err
+	}
+	for _, elseIf := range stmt.ElseIfs {
+		p.write(" illana " + expression(elseIf.Condition, LOWEST) + " " + keyword(elseIf.Piece, "endral") + " ")
+		if err := p.block(elseIf.Then); err != nil {
+			return err
+		}
+	}
+	if stmt.Else == nil {
+		return nil
+	}
+	p.write(" illana ")
+	return p.block(stmt.Else)
+}
+
+// Binding powers of parser/lookups.go, an operand is put in parentheses
+// when it binds weaker than the operator around it.
+const (
+	LOWEST = iota
+	COMMA
+	ASSIGNMENT
+	LOGICAL
+	RELATIONAL
+	ADDITIVE
+	MULTIPLICATIVE
+	UNARY
+	CALL
+	MEMBER
+	PRIMARY
+)
+
+var binaryPrecedence = map[lexer.PieceType]int{
+	lexer.And:          LOGICAL,
+	lexer.Or:           LOGICAL,
+	lexer.Equal:        RELATIONAL,
+	lexer.NotEqual:     RELATIONAL,
+	lexer.Less:         RELATIONAL,
+	lexer.Greater:      RELATIONAL,
+	lexer.LessEqual:    RELATIONAL,
+	lexer.GreaterEqual: RELATIONAL,
+	lexer.Plus:         ADDITIVE,
+	lexer.Minus:        ADDITIVE,
+	lexer.Star:         MULTIPLICATIVE,
+	lexer.Slash:        MULTIPLICATIVE,
+	lexer.Percent:      MULTIPLICATIVE,
+}
+
+func precedence(expr tree.Expr) int {
+	switch expr := expr.(type) {
+	case *tree.Binary:
+		return binaryPrecedence[expr.Operator.Kind]
+	case *tree.Assign:
+		return ASSIGNMENT
+	case *tree.Prefix:
+		return UNARY
+	case *tree.Length, *tree.Print:
+		return CALL
+	case *tree.Access, *tree.Call:
+		return MEMBER
+	}
+	return PRIMARY
+}
+
+// expression renders expr so that it parses back to the same tree when
+// it appears where operators binding at least min are expected.
+func expression(expr tree.Expr, min int) string {
+	s := render(expr)
+	if precedence(expr) < min {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func render(expr tree.Expr) string {
+	switch expr := expr.(type) {
+	case *tree.Identifier:
+		return expr.Name
+	case *tree.Number:
+		return strconv.FormatInt(expr.Value, 10)
+	case *tree.Float:
+		return expr.String()
+	case *tree.StringLiteral:
+		return `"` + expr.Value + `"`
+	case *tree.Boolean:
+		if expr.Value {
+			return keyword(expr.Piece, "aam")
+		}
+		return keyword(expr.Piece, "illai")
+	case *tree.Access:
+		return expression(expr.Left, MEMBER) + "[" + expression(expr.Index, LOWEST) + "]"
+	case *tree.Length:
+		return expression(expr.Value, CALL) + " " + keyword(expr.Piece, "neelam")
+	case *tree.Print:
+		return expression(expr.Value, CALL) + " " + keyword(expr.Piece, "sollu")
+	case *tree.Prefix:
+		right := expression(expr.Right, UNARY)
+		if _, nested := expr.Right.(*tree.Prefix); nested {
+			right = "(" + right + ")"
+		}
+		return expr.Operator.Value + right
+	case *tree.Binary:
+		bp := binaryPrecedence[expr.Operator.Kind]
+		// operators are left associative, an operand on the right of
+		// the same precedence needs parentheses
+		return expression(expr.Left, bp) + " " + expr.Operator.Value + " " + expression(expr.Right, bp+1)
+	case *tree.Assign:
+		return expr.Left.Name + " = " + expression(expr.Right, ASSIGNMENT+1)
+	case *tree.Call:
+		args := []string{}
+		for _, arg := range expr.Args {
+			args = append(args, expression(arg, LOWEST))
+		}
+		return expr.Function.Name + "(" + strings.Join(args, ", ") + ")"
+	case *tree.Array:
+		elements := []string{}
+		for _, element := range expr.Elements {
+			elements = append(elements, expression(element, LOWEST))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	return expr.String()
+}
+
+// keyword keeps the spelling used in the source, which may be Tamil.
+func keyword(piece lexer.Piece, fallback string) string {
+	if piece.Value != "" {
+		return piece.Value
+	}
+	return fallback
+}
+
+func datatypeKeyword(datatype string) string {
+	switch datatype {
+	case "INTEGER":
+		return "yen"
+	case "STRING":
+		return "sol"
+	case "ARRAY":
+		return "varisai"
+	case "FLOAT":
+		return "thasamam"
+	case "HASH":
+		return "agarathi"
+	}
+	return datatype
+}
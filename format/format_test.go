@@ -0,0 +1,109 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+)
+
+var tests = []struct {
+	name     string
+	input    string
+	expected string
+}{
+	{"spacing", `yen a=1+2*3;sol s="x";`, "yen a = 1 + 2 * 3;\nsol s = \"x\";\n"},
+	{"redundant parentheses", `yen a = (1 + (2 * 3)); a = ((a));`, "yen a = 1 + 2 * 3;\na = a;\n"},
+	{"needed parentheses", `yen a = (1 + 2) * (3 - (4 - 5)); yen b = -(a + 1);`,
+		"yen a = (1 + 2) * (3 - (4 - 5));\nyen b = -(a + 1);\n"},
+	{"left associative", `yen a = 1 - 2 - 3;`, "yen a = 1 - 2 - 3;\n"},
+	{"postfix", `sol s = "ab"; s neelam sollu; s[1] sollu; 1 + (s neelam) sollu;`,
+		"sol s = \"ab\";\ns neelam sollu;\ns[1] sollu;\n1 + s neelam sollu;\n"},
+	{"print lists", `1 ,2,"x"  sollu;"a"solli;1, 2 சொல்லி;`, "1, 2, \"x\" sollu;\n\"a\" solli;\n1, 2 சொல்லி;\n"},
+	{"logical", `aam&&!illai||illai sollu;`, "aam && !illai || illai sollu;\n"},
+	{"input", `yen a   kodu ;`, "yen a kodu;\n"},
+	{"prompts", `sol s="Name? "kodu;என் n = s+":" கொடு;`, "sol s = \"Name? \" kodu;\nyen n = s + \":\" கொடு;\n"},
+	{"calls", `assert_equal( (1+2) ,3 ) ; assert_true(aam);yen a=assert_true((illai))neelam;`,
+		"assert_equal(1 + 2, 3);\nassert_true(aam);\nyen a = assert_true(illai) neelam;\n"},
+	{"floats", `thasamam f=2.50;f*1.0 sollu;தசமம் g kodu;`, "thasamam f = 2.5;\nf * 1.0 sollu;\nthasamam g kodu;\n"},
+	{"hashes", `அகராதி h=json_decode("{}");h["a"]sollu;`, "அகராதி h = json_decode(\"{}\");\nh[\"a\"] sollu;\n"},
+	{"arrays", `varisai a=[1,"x" ,[ ]];a[0]sollu;[a neelam]sollu;`, "varisai a = [1, \"x\", []];\na[0] sollu;\n[a neelam] sollu;\n"},
+	{"imports", `"lib.n"   irakkumathi ;"வணக்கம்.n" இறக்குமதி;`, "\"lib.n\" irakkumathi;\n\"வணக்கம்.n\" இறக்குமதி;\n"},
+	{"blocks", `aam endral { "a" sollu; 1 murai { "b" sollu; } } illana { }`,
+		"aam endral {\n    \"a\" sollu;\n    1 murai {\n        \"b\" sollu;\n    }\n} illana {}\n"},
+	{"else if", "1 > 2 endral { } illana 2 > 1 endral { \"x\" sollu; } illana { \"y\" sollu; }",
+		"1 > 2 endral {} illana 2 > 1 endral {\n    \"x\" sollu;\n} illana {\n    \"y\" sollu;\n}\n"},
+	{"else if chain", "aam என்றால் {} இல்லனா illai என்றால் {} illana 1 < 2 endral {\"z\" sollu;}",
+		"aam என்றால் {} illana illai என்றால் {} illana 1 < 2 endral {\n    \"z\" sollu;\n}\n"},
+	{"branches", `aam varaikkum{ 1 murai {thodar ;} நிறுத்து;}`,
+		"aam varaikkum {\n    1 murai {\n        thodar;\n    }\n    நிறுத்து;\n}\n"},
+	{"try", `muyarchi{"x" eri;}pidi e {e["message"] sollu;} முயற்சி {} பிடி e {} iruthiyaga {"f" எறி;}`,
+		"muyarchi {\n    \"x\" eri;\n} pidi e {\n    e[\"message\"] sollu;\n}\nமுயற்சி {} பிடி e {} iruthiyaga {\n    \"f\" எறி;\n}\n"},
+	{"each", `xs ovvonrum x{x sollu;} range(0,2) ஒவ்வொன்றும் i ,n {}`,
+		"xs ovvonrum x {\n    x sollu;\n}\nrange(0, 2) ஒவ்வொன்றும் i, n {}\n"},
+	{"while", "yen i = 0;\ni < 2 varaikkum {\ni = i + 1;\n}", "yen i = 0;\ni < 2 varaikkum {\n    i = i + 1;\n}\n"},
+	{"blank lines", "yen a = 1;\n\n\n\nyen b = 2;\nyen c = 3;\n", "yen a = 1;\n\nyen b = 2;\nyen c = 3;\n"},
+	{"comments", "// head\nyen a = 1; // one\n\n// before\na sollu;\n// tail\n",
+		"// head\nyen a = 1; // one\n\n// before\na sollu;\n// tail\n"},
+	{"comments in blocks", "aam endral {\n// only\n}\n2 murai {\na sollu;\n   // after\n} // done\n",
+		"aam endral {\n    // only\n}\n2 murai {\n    a sollu;\n    // after\n} // done\n"},
+	{"empty", "", ""},
+	{"only comments", "// a\n\n// b", "// a\n\n// b\n"},
+}
+
+func TestSource(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := Source(first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(first) != string(second) {
+				t.Errorf("formatting twice changed the output\n%s\n%s", first, second)
+			}
+		})
+	}
+}
+
+func TestPreservesTree(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parse(tt.input) != parse(string(out)) {
+				t.Errorf("formatting changed the program\n%s\n%s", parse(tt.input), parse(string(out)))
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	if _, err := Source([]byte(`yen a = ;`)); err == nil {
+		t.Errorf("expected a syntax error")
+	}
+}
+
+func parse(input string) string {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return parser.Parse(channel, false).String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected files in testdata")
+
+// TestGolden checks each program of testdata against the files next to
+// it: name.tokens holds the pieces of the lexer, name.tree the tree
+// drawn by Program.Print, or the syntax error, and name.stdout what the
+// evaluator printed, reading name.stdin when there is one. Run with
+// -update to rewrite them after an intended change.
+func TestGolden(t *testing.T) {
+	programs, err := filepath.Glob(filepath.Join("testdata", "*.n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(programs) == 0 {
+		t.Fatal("no programs in testdata")
+	}
+	for _, path := range programs {
+		name := strings.TrimSuffix(path, ".n")
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, name+".tokens", tokens(source))
+			drawing, program := parseTree(source)
+			golden(t, name+".tree", drawing)
+			if program == nil {
+				return
+			}
+			stdin, err := os.ReadFile(name + ".stdin")
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			interpreter := &evaluator.Interpreter{
+				In:    bytes.NewReader(stdin),
+				Out:   out,
+				Path:  path,
+				Rand:  rand.New(rand.NewSource(1)),
+				Files: "testdata",
+			}
+			if err := interpreter.Run(program, object.NewEnvironment()); err != nil {
+				fmt.Fprintln(out, err)
+			}
+			golden(t, name+".stdout", out.String())
+		})
+	}
+}
+
+// tokens lists the pieces of the source, one per line with its position.
+func tokens(source []byte) string {
+	out := &strings.Builder{}
+	_, channel := lexer.CreateLexer(source, false)
+	for piece := range channel {
+		fmt.Fprintf(out, "%s %s\n", piece.Pos, piece)
+	}
+	return out.String()
+}
+
+// parseTree returns the drawing of the tree, or the syntax error.
+func parseTree(source []byte) (string, *tree.Program) {
+	_, channel := lexer.CreateLexer(source, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		return err.Error() + "\n", nil
+	}
+	return program.Print(0, "", ""), program
+}
+
+func golden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s, run go test -run TestGolden -update", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs, run go test -run TestGolden -update after checking the change\ngot\n%s\nwant\n%s", path, got, want)
+	}
+}
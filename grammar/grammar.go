@@ -0,0 +1,155 @@
+// Package grammar builds the TextMate grammar of the VS Code extension
+// from the keyword and operator tables of the lexer, so highlighting
+// follows the words the lexer actually knows.
+package grammar
+
+//go:generate go test -run TestGrammarIsCurrent -update
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/lexer"
+)
+
+// Path is where the extension expects the grammar, relative to the root
+// of the repository.
+const Path = "extension/niral/syntaxes/niral.tmLanguage.json"
+
+type pattern struct {
+	Name     string    `json:"name,omitempty"`
+	Match    string    `json:"match,omitempty"`
+	Begin    string    `json:"begin,omitempty"`
+	End      string    `json:"end,omitempty"`
+	Include  string    `json:"include,omitempty"`
+	Patterns []pattern `json:"patterns,omitempty"`
+}
+
+type language struct {
+	Schema     string             `json:"$schema"`
+	Comment    string             `json:"comment"`
+	Name       string             `json:"name"`
+	ScopeName  string             `json:"scopeName"`
+	FileTypes  []string           `json:"fileTypes"`
+	Patterns   []pattern          `json:"patterns"`
+	Repository map[string]pattern `json:"repository"`
+}
+
+// keywordScopes names the scope of the words of each kind.
+var keywordScopes = []struct {
+	rule  string
+	scope string
+	kinds []lexer.PieceType
+}{
+	{"types", "storage.type.n", []lexer.PieceType{lexer.DataType}},
+	{"constants", "constant.language.boolean.n", []lexer.PieceType{lexer.Boolean}},
+	{"control", "keyword.control.n", []lexer.PieceType{
+		lexer.If, lexer.Else, lexer.While, lexer.For, lexer.Each, lexer.Break, lexer.Continue,
+		lexer.Try, lexer.Catch, lexer.Finally, lexer.Raise,
+	}},
+	{"builtins", "support.function.builtin.n", []lexer.PieceType{lexer.Print, lexer.Input, lexer.Length}},
+	{"imports", "keyword.control.import.n", []lexer.PieceType{lexer.Import}},
+}
+
+// operatorScopes names the scope of the operators and punctuation of each
+// kind, kinds missing here are highlighted as operators.
+var operatorScopes = map[lexer.PieceType]string{
+	lexer.Eol:          "punctuation.terminator.n",
+	lexer.ParanOpen:    "punctuation.section.parens.n",
+	lexer.ParanClose:   "punctuation.section.parens.n",
+	lexer.BraceOpen:    "punctuation.section.braces.n",
+	lexer.BraceClose:   "punctuation.section.braces.n",
+	lexer.BracketOpen:  "punctuation.section.brackets.n",
+	lexer.BracketClose: "punctuation.section.brackets.n",
+	lexer.Comma:        "punctuation.separator.comma.n",
+}
+
+// letter matches a character of a word, Tamil vowel signs are marks.
+const letter = `[\p{L}\p{M}\p{N}_]`
+
+// Generate returns the grammar as indented JSON.
+func Generate() ([]byte, error) {
+	grammar := language{
+		Schema:     "https://raw.githubusercontent.com/martinring/tmlanguage/master/tmlanguage.json",
+		Comment:    "Generated from the lexer by go generate ./grammar, do not edit.",
+		Name:       "Niral",
+		ScopeName:  "source.n",
+		FileTypes:  []string{"n"},
+		Repository: map[string]pattern{},
+	}
+	add := func(rule string, p pattern) {
+		grammar.Patterns = append(grammar.Patterns, pattern{Include: "#" + rule})
+		grammar.Repository[rule] = p
+	}
+
+	add("comments", pattern{Name: "comment.line.double-slash.n", Match: `//.*$`})
+	add("strings", pattern{Name: "string.quoted.double.n", Begin: `"`, End: `"`})
+	keywords := lexer.Keywords()
+	for _, rule := range keywordScopes {
+		words := []string{}
+		for _, keyword := range keywords {
+			for _, kind := range rule.kinds {
+				if keyword.Kind == kind {
+					words = append(words, keyword.Tanglish)
+					if keyword.Tamil != "" {
+						words = append(words, keyword.Tamil)
+					}
+				}
+			}
+		}
+		add(rule.rule, pattern{Name: rule.scope, Match: "(?<!" + letter + ")" + alternatives(words) + "(?!" + letter + ")"})
+	}
+	add("floats", pattern{Name: "constant.numeric.float.n", Match: "(?<!" + letter + ")[0-9]+\\.[0-9]+(?!" + letter + ")"})
+	add("numbers", pattern{Name: "constant.numeric.integer.n", Match: "(?<!" + letter + ")[0-9]+(?!" + letter + ")"})
+
+	symbols := map[string][]string{}
+	for operator, kind := range lexer.Operators() {
+		scope, ok := operatorScopes[kind]
+		if !ok {
+			scope = "keyword.operator.n"
+		}
+		symbols[scope] = append(symbols[scope], operator)
+	}
+	scopes := []string{}
+	for scope := range symbols {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	operators := pattern{}
+	for _, scope := range scopes {
+		operators.Patterns = append(operators.Patterns, pattern{Name: scope, Match: alternatives(symbols[scope])})
+	}
+	add("operators", operators)
+	add("calls", pattern{Name: "entity.name.function.n", Match: letter + `+(?=\s*\()`})
+	add("identifiers", pattern{Name: "variable.other.n", Match: letter + "+"})
+
+	out := &bytes.Buffer{}
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(grammar); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// alternatives matches any of the words, longer words are tried first so
+// that == is not matched as two =.
+func alternatives(words []string) string {
+	sort.Slice(words, func(i, j int) bool {
+		a, b := utf8.RuneCountInString(words[i]), utf8.RuneCountInString(words[j])
+		if a != b {
+			return a > b
+		}
+		return words[i] < words[j]
+	})
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
@@ -0,0 +1,73 @@
+package grammar
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the checked in grammar")
+
+func TestGrammarIsCurrent(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("..", Path)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, generated, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkedIn, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(checkedIn, generated) {
+		t.Errorf("%s is stale, run go generate ./grammar", Path)
+	}
+}
+
+func TestGrammar(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	grammar := language{}
+	if err := json.Unmarshal(generated, &grammar); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rule  string
+		words []string
+	}{
+		{"types", []string{"yen", "sol", "என்", "சொல்"}},
+		{"control", []string{"endral", "illana", "varaikkum", "murai", "என்றால்"}},
+		{"builtins", []string{"sollu", "kodu", "neelam", "சொல்லு"}},
+		{"constants", []string{"aam", "illai", "ஆம்"}},
+	}
+	for _, tt := range tests {
+		match := grammar.Repository[tt.rule].Match
+		// Go has no lookbehind, the word alternatives are enough here
+		words := regexp.MustCompile("^" + match[strings.Index(match, "(?:"):strings.LastIndex(match, ")(?!")+1] + "$")
+		for _, word := range tt.words {
+			if !words.MatchString(word) {
+				t.Errorf("%s does not match %s", tt.rule, word)
+			}
+		}
+	}
+	if control := grammar.Repository["control"].Match; strings.Index(control, "varaikkum") > strings.Index(control, "murai") {
+		t.Errorf("longer words should come first: %s", control)
+	}
+	if operators := grammar.Repository["operators"].Patterns[0].Match; operators != `(?:!=|&&|<=|==|>=|\|\||!|%|&|\*|\+|-|/|<|=|>|\|)` {
+		t.Errorf("unexpected operators: %s", operators)
+	}
+}
@@ -0,0 +1,86 @@
+// Package grapheme splits text into the letters a reader sees. A letter
+// is a base character with the marks following it, so the Tamil கி, a
+// consonant with a vowel sign, and க், a consonant with a pulli, are one
+// letter each. This follows the extended grapheme clusters of Unicode
+// for the scripts the language is written in, and keeps CR LF, emoji
+// joined by a zero width joiner and flags of two regional indicators
+// together.
+package grapheme
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zwnj = '\u200c' // zero width non-joiner
+	zwj  = '\u200d' // zero width joiner
+)
+
+// Split returns the letters of s in order, joining them gives s back.
+func Split(s string) []string {
+	letters := []string{}
+	for len(s) > 0 {
+		n := next(s)
+		letters = append(letters, s[:n])
+		s = s[n:]
+	}
+	return letters
+}
+
+// Count returns the number of letters in s.
+func Count(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[next(s):]
+		count++
+	}
+	return count
+}
+
+// next returns the length in bytes of the letter starting s.
+func next(s string) int {
+	first, n := utf8.DecodeRuneInString(s)
+	if first == '\r' && n < len(s) && s[n] == '\n' {
+		return n + 1
+	}
+	if first == '\r' || first == '\n' {
+		return n
+	}
+	prev := first
+	flags := 0
+	if regional(first) {
+		flags = 1
+	}
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case extend(r):
+		case prev == zwj && unicode.Is(unicode.So, r):
+			// an emoji sequence like a family
+		case flags == 1 && regional(r):
+			flags++
+		default:
+			return n
+		}
+		prev = r
+		n += size
+	}
+	return n
+}
+
+// extend reports whether r attaches to the letter before it.
+func extend(r rune) bool {
+	switch {
+	case r == zwj || r == zwnj:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		// skin tone modifiers
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me)
+}
+
+func regional(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
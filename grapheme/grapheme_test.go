@@ -0,0 +1,36 @@
+package grapheme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"abc", []string{"a", "b", "c"}},
+		{"தமிழ்", []string{"த", "மி", "ழ்"}},
+		{"வணக்கம்", []string{"வ", "ண", "க்", "க", "ம்"}},
+		{"கௌ", []string{"கௌ"}},
+		{"a\r\nb\n", []string{"a", "\r\n", "b", "\n"}},
+		{"é", []string{"é"}},
+		{"👨‍👩‍👧", []string{"👨‍👩‍👧"}},
+		{"👍🏽!", []string{"👍🏽", "!"}},
+		{"🇮🇳🇱🇰", []string{"🇮🇳", "🇱🇰"}},
+		// a mark without a base is a letter of its own
+		{"்க", []string{"்", "க"}},
+		{"\xffa", []string{"\xff", "a"}},
+	}
+	for _, tt := range tests {
+		got := Split(tt.input)
+		if strings.Join(got, "|") != strings.Join(tt.expected, "|") || len(got) != len(tt.expected) {
+			t.Errorf("%q: got %q, want %q", tt.input, got, tt.expected)
+		}
+		if Count(tt.input) != len(tt.expected) {
+			t.Errorf("%q: counted %d letters, want %d", tt.input, Count(tt.input), len(tt.expected))
+		}
+	}
+}
@@ -3,7 +3,7 @@ package lexer
 type consumer func(*Lexer) consumer
 
 func consumeAlphaNumeric(lex *Lexer) consumer {
-	lex.takeMany(alpha + numeric)
+	lex.takeManyFunc(isLetterOrDigit)
 	word := string(lex.input[lex.start:lex.cur])
 	if kind, ok := kindOf[word]; ok {
 		lex.send(kind)
@@ -14,19 +14,29 @@ func consumeAlphaNumeric(lex *Lexer) consumer {
 }
 
 func consumeString(lex *Lexer) consumer {
-	lex.ignore() // consume the opening "
-	for lex.input[lex.cur] != '"' {
-		lex.next()
+	// the opening " is already consumed
+	for lex.cur >= len(lex.input) || lex.input[lex.cur] != '"' {
 		if lex.cur >= len(lex.input) {
+			// an unterminated string, the end of file still follows
 			lex.send(Unknown)
-			return nil
+			return initial
 		}
+		lex.next()
 	}
-	lex.send(StringLiteral)
+	value := string(lex.input[lex.start+1 : lex.cur])
 
 	// consume the closing "
 	lex.next()
-	lex.ignore()
+	lex.sendValue(StringLiteral, value)
 
 	return initial
 }
+
+func consumeComment(lex *Lexer) consumer {
+	// the opening // is already consumed
+	for lex.cur < len(lex.input) && lex.input[lex.cur] != '\n' {
+		lex.next()
+	}
+	lex.send(Comment)
+	return initial
+}
@@ -0,0 +1,46 @@
+package lexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// seed adds the programs of the golden corpus to the fuzzer.
+func seed(f *testing.F) {
+	programs, err := filepath.Glob(filepath.Join("..", "testdata", "*.n"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range programs {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(source)
+	}
+	// a word right at the end of the input once made the lexer go back forever
+	f.Add([]byte("a sollu"))
+}
+
+func FuzzLexer(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, source []byte) {
+		_, channel := CreateLexer(source, false)
+		last := Piece{}
+		count := 0
+		for piece := range channel {
+			if last.Kind == Eof && count > 0 {
+				t.Fatalf("%s after the end of file", piece)
+			}
+			if piece.Pos.Offset < last.Pos.Offset || piece.Pos.Offset > len(source) {
+				t.Fatalf("%s at offset %d after offset %d", piece, piece.Pos.Offset, last.Pos.Offset)
+			}
+			last = piece
+			count++
+		}
+		if count == 0 || last.Kind != Eof {
+			t.Fatalf("the last piece is %s, want the end of file", last)
+		}
+	})
+}
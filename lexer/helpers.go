@@ -7,14 +7,35 @@ import (
 )
 
 func (lex *Lexer) send(p PieceType) {
-	piece := Piece{p, string(lex.input[lex.start:lex.cur])}
-	if (lex.log) {
+	lex.sendValue(p, string(lex.input[lex.start:lex.cur]))
+}
+
+// sendValue sends a piece spanning start to cur whose value differs from
+// the source text, like a string literal without its quotes.
+func (lex *Lexer) sendValue(p PieceType, value string) {
+	pos := lex.pos
+	lex.advance()
+	piece := Piece{Kind: p, Value: value, Pos: pos, End: lex.pos}
+	if lex.log {
 		fmt.Println(piece)
 	}
 	lex.channel <- piece
 	lex.start = lex.cur
 }
 
+// advance moves pos over the input between start and cur.
+func (lex *Lexer) advance() {
+	for _, r := range string(lex.input[lex.start:lex.cur]) {
+		if r == '\n' {
+			lex.pos.Line++
+			lex.pos.Column = 1
+		} else {
+			lex.pos.Column++
+		}
+	}
+	lex.pos.Offset = lex.cur
+}
+
 func (lex *Lexer) sendIf(yes bool, p PieceType) {
 	if yes {
 		lex.send(p)
@@ -50,6 +71,7 @@ func (lex *Lexer) peek() rune {
 }
 
 func (lex *Lexer) ignore() {
+	lex.advance()
 	lex.start = lex.cur
 }
 
@@ -66,3 +88,20 @@ func (lex *Lexer) takeMany(valid string) {
 	}
 	lex.goBack()
 }
+
+func (lex *Lexer) takeOneFunc(valid func(rune) bool) bool {
+	// at the end there is nothing to go back over
+	if lex.cur >= len(lex.input) {
+		return false
+	}
+	if valid(lex.next()) {
+		return true
+	}
+	lex.goBack()
+	return false
+}
+
+func (lex *Lexer) takeManyFunc(valid func(rune) bool) {
+	for lex.takeOneFunc(valid) {
+	}
+}
@@ -1,5 +1,10 @@
 package lexer
 
+import (
+	"strings"
+	"unicode"
+)
+
 type Lexer struct {
 	input   []byte
 	start   int // start position of the piece
@@ -7,6 +12,7 @@ type Lexer struct {
 	size    int // size of the current piece
 	channel chan Piece
 	log     bool
+	pos     Position // position of start, advanced by send and ignore
 }
 
 func CreateLexer(input []byte, lexLog bool) (*Lexer, chan Piece) {
@@ -14,6 +20,7 @@ func CreateLexer(input []byte, lexLog bool) (*Lexer, chan Piece) {
 		input:   input,
 		channel: make(chan Piece),
 		log:     lexLog,
+		pos:     Position{Line: 1, Column: 1},
 	}
 	go lex.run()
 	return lex, lex.channel
@@ -31,6 +38,16 @@ const (
 	numeric = "0123456789"
 )
 
+// isLetter reports whether r can start a word, words may be written in
+// English letters or in Tamil script.
+func isLetter(r rune) bool {
+	return strings.ContainsRune(alpha, r) || unicode.Is(unicode.Tamil, r)
+}
+
+func isLetterOrDigit(r rune) bool {
+	return isLetter(r) || strings.ContainsRune(numeric, r)
+}
+
 func initial(lex *Lexer) consumer {
 	for {
 		if lex.cur >= len(lex.input) {
@@ -46,18 +63,26 @@ func initial(lex *Lexer) consumer {
 			lex.ignore()
 			continue
 		}
-		if lex.takeOne(alpha) {
+		if lex.takeOneFunc(isLetter) {
 			return consumeAlphaNumeric
 		}
 		if lex.takeOne("0123456789") {
 			lex.takeMany(numeric)
+			lex.takeFraction()
 			lex.send(Number)
 			continue
 		}
 		if lex.takeOne("\"") {
 			return consumeString
 		}
-		if lex.takeOne("+*-/%(){}[]") {
+		if lex.takeOne("/") {
+			if lex.takeOne("/") {
+				return consumeComment
+			}
+			lex.send(Slash)
+			continue
+		}
+		if lex.takeOne("+*-%(){}[],") {
 			val := string(lex.input[lex.start:lex.cur])
 			lex.send(kindOf[val])
 			continue
@@ -83,3 +108,12 @@ func initial(lex *Lexer) consumer {
 		}
 	}
 }
+
+// takeFraction takes the point and digits of a number like 2.5, a point
+// not followed by a digit is left alone.
+func (lex *Lexer) takeFraction() {
+	if lex.cur+1 < len(lex.input) && lex.input[lex.cur] == '.' && strings.IndexByte(numeric, lex.input[lex.cur+1]) >= 0 {
+		lex.next()
+		lex.takeMany(numeric)
+	}
+}
@@ -1,22 +1,34 @@
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type PieceType int
 
 const (
 	Eof PieceType = iota
 	Eol
+	Comment
 
 	DataType
 	If
 	Else
 	While
 	For
+	Each
+	Break
+	Continue
+	Try
+	Catch
+	Finally
+	Raise
 
 	Print
 	Input
 	Length
+	Import
 
 	Identifier
 	Number
@@ -47,6 +59,7 @@ const (
 	BraceClose
 	BracketOpen
 	BracketClose
+	Comma
 
 	Unknown
 )
@@ -55,17 +68,33 @@ var kindOf = map[string]PieceType{
 	// Keywords
 	"yen":       DataType,
 	"sol":       DataType,
+	"varisai":   DataType,
+	"thasamam":  DataType,
+	"agarathi":  DataType,
 	"aam":       Boolean,
 	"illai":     Boolean,
 	"endral":    If,
 	"illana":    Else,
 	"varaikkum": While,
 	"murai":     For,
+	"ovvonrum":  Each,
+	"niruthu":   Break,
+	"thodar":    Continue,
+
+	// errors
+	"muyarchi":   Try,
+	"pidi":       Catch,
+	"iruthiyaga": Finally,
+	"eri":        Raise,
 
 	// builtins
-	"sollu":     Print,
-	"kodu":      Input,
-	"neelam":    Length,
+	"sollu":  Print,
+	"solli":  Print,
+	"kodu":   Input,
+	"neelam": Length,
+
+	// modules
+	"irakkumathi": Import,
 
 	// operators
 	"+": Plus,
@@ -85,6 +114,8 @@ var kindOf = map[string]PieceType{
 	"!=": NotEqual,
 	"<=": LessEqual,
 	">=": GreaterEqual,
+	"&&": And,
+	"||": Or,
 
 	// Grouping
 	"(": ParanOpen,
@@ -93,13 +124,121 @@ var kindOf = map[string]PieceType{
 	"}": BraceClose,
 	"[": BracketOpen,
 	"]": BracketClose,
+	",": Comma,
 
 	";": Eol,
 }
 
+// tamil spells the keywords in Tamil script, each maps to the Tanglish
+// keyword it stands for.
+var tamil = map[string]string{
+	"என்":         "yen",
+	"சொல்":        "sol",
+	"வரிசை":       "varisai",
+	"தசமம்":       "thasamam",
+	"அகராதி":      "agarathi",
+	"ஆம்":         "aam",
+	"இல்லை":       "illai",
+	"என்றால்":     "endral",
+	"இல்லனா":      "illana",
+	"வரைக்கும்":   "varaikkum",
+	"முறை":        "murai",
+	"ஒவ்வொன்றும்": "ovvonrum",
+	"நிறுத்து":    "niruthu",
+	"தொடர்":       "thodar",
+	"முயற்சி":     "muyarchi",
+	"பிடி":        "pidi",
+	"இறுதியாக":    "iruthiyaga",
+	"எறி":         "eri",
+	"சொல்லு":      "sollu",
+	"சொல்லி":      "solli",
+	"கொடு":        "kodu",
+	"நீளம்":       "neelam",
+	"இறக்குமதி":   "irakkumathi",
+}
+
+func init() {
+	for word, tanglish := range tamil {
+		kindOf[word] = kindOf[tanglish]
+	}
+}
+
+// Keyword is a word reserved by the language with both of its spellings.
+type Keyword struct {
+	Tanglish string
+	Tamil    string
+	Kind     PieceType
+}
+
+// Keywords returns the words reserved by the language, sorted by their
+// Tanglish spelling. Tamil is empty for a keyword without a Tamil spelling.
+func Keywords() []Keyword {
+	spelling := map[string]string{}
+	for tamilWord, tanglish := range tamil {
+		spelling[tanglish] = tamilWord
+	}
+	keywords := []Keyword{}
+	for word, kind := range kindOf {
+		if _, isTamil := tamil[word]; isTamil || !isLetter([]rune(word)[0]) {
+			continue
+		}
+		keywords = append(keywords, Keyword{Tanglish: word, Tamil: spelling[word], Kind: kind})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		return keywords[i].Tanglish < keywords[j].Tanglish
+	})
+	return keywords
+}
+
+// Operators returns the operators and punctuation of the language mapped
+// to their kind.
+func Operators() map[string]PieceType {
+	operators := map[string]PieceType{}
+	for word, kind := range kindOf {
+		if !isLetter([]rune(word)[0]) {
+			operators[word] = kind
+		}
+	}
+	return operators
+}
+
+// Tanglish returns the Tanglish spelling of a keyword written in Tamil,
+// any other word is returned as it is.
+func Tanglish(word string) string {
+	if tanglish, ok := tamil[word]; ok {
+		return tanglish
+	}
+	return word
+}
+
 type Piece struct {
 	Kind  PieceType
 	Value string
+	Pos   Position // first character of the piece
+	End   Position // just after the last character of the piece
+}
+
+// Position is a location in the source. Lines and columns start at 1 and
+// columns count runes, so a Tamil letter is a single column.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// IsValid reports whether the position was set by the lexer.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Lookup returns the kind of a keyword or operator.
+func Lookup(word string) (PieceType, bool) {
+	kind, ok := kindOf[word]
+	return kind, ok
 }
 
 func (p Piece) String() string {
@@ -164,14 +303,34 @@ func (p Piece) String() string {
 		return fmt.Sprintf("bracket open: %s", p.Value)
 	case BracketClose:
 		return fmt.Sprintf("bracket close: %s", p.Value)
+	case Comma:
+		return fmt.Sprintf("comma: %s", p.Value)
 	case Print:
 		return fmt.Sprintf("print: %s", p.Value)
 	case Input:
 		return fmt.Sprintf("input: %s", p.Value)
+	case Import:
+		return fmt.Sprintf("import: %s", p.Value)
 	case If:
 		return fmt.Sprintf("if: %s", p.Value)
 	case Else:
 		return fmt.Sprintf("else: %s", p.Value)
+	case Each:
+		return fmt.Sprintf("each: %s", p.Value)
+	case Break:
+		return fmt.Sprintf("break: %s", p.Value)
+	case Continue:
+		return fmt.Sprintf("continue: %s", p.Value)
+	case Try:
+		return fmt.Sprintf("try: %s", p.Value)
+	case Catch:
+		return fmt.Sprintf("catch: %s", p.Value)
+	case Finally:
+		return fmt.Sprintf("finally: %s", p.Value)
+	case Raise:
+		return fmt.Sprintf("raise: %s", p.Value)
+	case Comment:
+		return fmt.Sprintf("comment: %s", p.Value)
 	case Eol:
 		return ";"
 	case Eof:
@@ -0,0 +1,280 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/checker"
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// document is an open file. What the checker found is kept from the last
+// time the text parsed, so hover and go-to-definition keep working while
+// a line is half typed. The text is the one that parsed, the offsets in
+// the info point into it and not into the half typed line.
+type document struct {
+	uri  string
+	text string
+	info *checker.Info
+}
+
+// update parses the new text and returns its diagnostics.
+func (d *document) update(text string) []Diagnostic {
+	program, err := parse(text)
+	if err != nil {
+		diagnostic := Diagnostic{Severity: severityError, Source: "niral", Message: err.Error()}
+		if syntax, ok := err.(*parser.Error); ok {
+			diagnostic.Range = toRange(text, tree.Span{Start: syntax.Piece.Pos, End: syntax.Piece.End})
+			diagnostic.Message = syntax.Message
+		}
+		return []Diagnostic{diagnostic}
+	}
+	d.text = text
+	d.info = checker.CheckFile(program, d.path())
+	diagnostics := []Diagnostic{}
+	for _, found := range d.info.Diagnostics {
+		severity := severityError
+		if found.Severity == checker.Warning {
+			severity = severityWarning
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    toRange(d.text, found.Span),
+			Severity: severity,
+			Source:   "niral",
+			Message:  found.Message,
+		})
+	}
+	return diagnostics
+}
+
+// path is the file of the document, empty when it is not a file.
+func (d *document) path() string {
+	u, err := url.Parse(d.uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// parse keeps the server alive when the parser fails in a way it does not
+// report as a syntax error.
+func parse(text string) (program *tree.Program, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			program, err = nil, fmt.Errorf("parser failed: %v", r)
+		}
+	}()
+	_, channel := lexer.CreateLexer([]byte(text), false)
+	return parser.TryParse(channel, false)
+}
+
+// meaning describes each keyword for completion.
+var meaning = map[string]string{
+	"yen":         "integer variable",
+	"sol":         "string variable",
+	"varisai":     "array variable",
+	"thasamam":    "float variable",
+	"agarathi":    "hash variable, from strings to values",
+	"aam":         "true",
+	"illai":       "false",
+	"endral":      "if",
+	"illana":      "else",
+	"varaikkum":   "while loop",
+	"murai":       "repeat loop",
+	"ovvonrum":    "loop over each letter, element, key or number",
+	"niruthu":     "break out of the loop",
+	"thodar":      "continue with the next turn of the loop",
+	"muyarchi":    "try a block, an error in it goes to pidi",
+	"pidi":        "catch the error of muyarchi into a hash",
+	"iruthiyaga":  "finally, runs after muyarchi and pidi",
+	"eri":         "raise an error with a message",
+	"sollu":       "print",
+	"solli":       "print without a newline",
+	"kodu":        "read input",
+	"neelam":      "length",
+	"irakkumathi": "import a file",
+}
+
+func (d *document) completion() []CompletionItem {
+	items := []CompletionItem{}
+	for _, keyword := range lexer.Keywords() {
+		if keyword.Tamil == "" {
+			items = append(items, CompletionItem{Label: keyword.Tanglish, Kind: completionKeyword, Detail: meaning[keyword.Tanglish]})
+			continue
+		}
+		items = append(items,
+			CompletionItem{Label: keyword.Tanglish, Kind: completionKeyword, Detail: meaning[keyword.Tanglish] + " (" + keyword.Tamil + ")"},
+			CompletionItem{Label: keyword.Tamil, Kind: completionKeyword, Detail: meaning[keyword.Tanglish] + " (" + keyword.Tanglish + ")"},
+		)
+	}
+	names := []string{}
+	for name := range evaluator.Builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, CompletionItem{Label: name, Kind: completionFunction, Detail: signature(name)})
+	}
+	if d == nil || d.info == nil {
+		return items
+	}
+	seen := map[string]bool{}
+	for _, symbol := range d.info.Symbols {
+		if seen[symbol.Name] {
+			continue
+		}
+		seen[symbol.Name] = true
+		kind := completionVariable
+		if symbol.Kind == checker.Function {
+			kind = completionFunction
+		}
+		items = append(items, CompletionItem{Label: symbol.Name, Kind: kind, Detail: symbol.Datatype})
+	}
+	return items
+}
+
+// signature describes a builtin like name(INTEGER, STRING) BOOLEAN, ANY
+// stands for the arguments of any type.
+func signature(name string) string {
+	builtin := evaluator.Builtins[name]
+	params := []string{}
+	for _, param := range builtin.Params {
+		if param == "" {
+			param = "ANY"
+		}
+		params = append(params, string(param))
+	}
+	return fmt.Sprintf("%s(%s) %s", name, strings.Join(params, ", "), builtin.Result)
+}
+
+func (d *document) hover(pos Position) *Hover {
+	use, ok := d.useAt(pos)
+	if !ok {
+		return nil
+	}
+	symbol := use.Symbol
+	value := "```niral\n" + declaration(symbol) + "\n```"
+	if symbol.Datatype != "" {
+		value += fmt.Sprintf("\n\n%s, declared on line %d", symbol.Datatype, symbol.Decl.Pos.Line)
+	}
+	return &Hover{
+		Contents: markupContent{Kind: "markdown", Value: value},
+		Range:    toRange(d.text, use.Span),
+	}
+}
+
+// declaration is how the symbol was declared, in the spelling of the
+// source.
+func declaration(symbol *checker.Symbol) string {
+	switch node := symbol.Node.(type) {
+	case *tree.Declaration:
+		return node.Piece.Value + " " + symbol.Name
+	case *tree.Input:
+		return datatypeKeyword(node.DataType) + " " + symbol.Name + " " + node.Piece.Value
+	case *tree.Function:
+		return symbol.Name + " seiyal"
+	case *tree.EachStmt:
+		return node.Piece.Value + " " + symbol.Name
+	case *tree.TryStmt:
+		return node.Catch.Value + " " + symbol.Name
+	case *tree.ImportStmt:
+		return fmt.Sprintf("%s // %q %s", symbol.Name, node.Path.Value, node.Piece.Value)
+	}
+	return symbol.Name
+}
+
+func datatypeKeyword(datatype string) string {
+	switch datatype {
+	case "INTEGER":
+		return "yen"
+	case "STRING":
+		return "sol"
+	case "ARRAY":
+		return "varisai"
+	case "FLOAT":
+		return "thasamam"
+	case "HASH":
+		return "agarathi"
+	}
+	return datatype
+}
+
+func (d *document) definition(pos Position) *Location {
+	use, ok := d.useAt(pos)
+	if !ok {
+		return nil
+	}
+	decl := use.Symbol.Decl
+	return &Location{URI: d.uri, Range: toRange(d.text, tree.Span{Start: decl.Pos, End: decl.End})}
+}
+
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	if d == nil || d.info == nil {
+		return symbols
+	}
+	for _, symbol := range d.info.Symbols {
+		kind := symbolVariable
+		if symbol.Kind == checker.Function {
+			kind = symbolFunction
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           symbol.Name,
+			Detail:         symbol.Datatype,
+			Kind:           kind,
+			Range:          toRange(d.text, tree.SpanOf(symbol.Node)),
+			SelectionRange: toRange(d.text, tree.Span{Start: symbol.Decl.Pos, End: symbol.Decl.End}),
+		})
+	}
+	return symbols
+}
+
+func (d *document) useAt(pos Position) (checker.Use, bool) {
+	if d == nil || d.info == nil {
+		return checker.Use{}, false
+	}
+	return d.info.SymbolAt(lexer.Position{Offset: offset(d.text, pos), Line: pos.Line + 1})
+}
+
+// offset converts a position of the client to a byte offset in the text.
+func offset(text string, pos Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+	for units := 0; units < pos.Character && offset < len(text) && text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// toPosition converts a position of the lexer to the zero based line and
+// UTF-16 column used by the protocol.
+func toPosition(text string, pos lexer.Position) Position {
+	if !pos.IsValid() || pos.Offset > len(text) {
+		return Position{}
+	}
+	start := strings.LastIndexByte(text[:pos.Offset], '\n') + 1
+	return Position{
+		Line:      pos.Line - 1,
+		Character: len(utf16.Encode([]rune(text[start:pos.Offset]))),
+	}
+}
+
+func toRange(text string, span tree.Span) Range {
+	return Range{Start: toPosition(text, span.Start), End: toPosition(text, span.End)}
+}
@@ -0,0 +1,170 @@
+// Package lsp implements a Language Server Protocol server for Niral
+// programs, spoken over stdio by the `niral lsp` command.
+//
+// Go to definition finds the declarations of variables.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/iam-naveen/compiler/wire"
+)
+
+// Server answers the requests of one client.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, docs: map[string]*document{}}
+}
+
+// Serve answers requests until the client sends exit or closes the input.
+// It fails when the client exits without asking to shut down first.
+func (s *Server) Serve() error {
+	for {
+		body, err := wire.Read(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := request{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.fail(nil, parseError, err.Error())
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit before shutdown")
+			}
+			return nil
+		}
+		s.handle(req)
+	}
+}
+
+func (s *Server) write(message any) {
+	if err := wire.Write(s.out, message); err != nil {
+		panic(err)
+	}
+}
+
+func (s *Server) reply(id json.RawMessage, result any) {
+	s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) fail(id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params any) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) handle(req request) {
+	isRequest := req.ID != nil
+	if s.shutdown && isRequest {
+		s.fail(req.ID, invalidRequest, "the server is shutting down")
+		return
+	}
+	var result any
+	var err error
+	switch req.Method {
+	case "initialize":
+		result = initialize()
+	case "initialized":
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			s.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			// the server asks for full sync, the last change has the whole text
+			s.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		params := didCloseParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			delete(s.docs, params.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []Diagnostic{},
+			})
+		}
+	case "textDocument/completion":
+		params := positionParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.docs[params.TextDocument.URI].completion()
+		}
+	case "textDocument/hover":
+		params := positionParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if hover := s.docs[params.TextDocument.URI].hover(params.Position); hover != nil {
+				result = hover
+			}
+		}
+	case "textDocument/definition":
+		params := positionParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if location := s.docs[params.TextDocument.URI].definition(params.Position); location != nil {
+				result = location
+			}
+		}
+	case "textDocument/documentSymbol":
+		params := documentSymbolParams{}
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = s.docs[params.TextDocument.URI].symbols()
+		}
+	default:
+		// notifications the server does not know about are ignored, so
+		// are the optional ones starting with $/
+		if isRequest {
+			s.fail(req.ID, methodNotFound, "unknown method "+req.Method)
+		}
+		return
+	}
+	if !isRequest {
+		return
+	}
+	if err != nil {
+		s.fail(req.ID, invalidParams, err.Error())
+		return
+	}
+	s.reply(req.ID, result)
+}
+
+func initialize() initializeResult {
+	result := initializeResult{}
+	result.Capabilities.TextDocumentSync = textDocumentSyncFull
+	result.Capabilities.HoverProvider = true
+	result.Capabilities.DefinitionProvider = true
+	result.Capabilities.DocumentSymbolProvider = true
+	result.ServerInfo.Name = "niral"
+	return result
+}
+
+// open stores the text of the document and publishes its diagnostics.
+func (s *Server) open(uri, text string) {
+	doc := s.docs[uri]
+	if doc == nil {
+		doc = &document{uri: uri}
+		s.docs[uri] = doc
+	}
+	diagnostics := doc.update(text)
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
@@ -0,0 +1,202 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///a.n"
+
+// session sends the messages to a new server and returns what it wrote.
+func session(t *testing.T, messages ...string) []map[string]any {
+	t.Helper()
+	in := &bytes.Buffer{}
+	for _, message := range messages {
+		fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(message), message)
+	}
+	out := &bytes.Buffer{}
+	if err := NewServer(in, out).Serve(); err != nil {
+		t.Fatal(err)
+	}
+	replies := []map[string]any{}
+	reader := bufio.NewReader(out)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return replies
+		}
+		length := 0
+		fmt.Sscanf(header, "Content-Length: %d", &length)
+		reader.ReadString('\n')
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		reply := map[string]any{}
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatalf("%s: %s", body, err)
+		}
+		replies = append(replies, reply)
+	}
+}
+
+func open(text string) string {
+	data, _ := json.Marshal(text)
+	return `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` + uri + `","languageId":"niral","version":1,"text":` + string(data) + `}}}`
+}
+
+func at(id int, method string, line, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"textDocument/%s","params":{"textDocument":{"uri":"%s"},"position":{"line":%d,"character":%d}}}`,
+		id, method, uri, line, character)
+}
+
+// result returns the result of the reply to the request id.
+func result(t *testing.T, replies []map[string]any, id int) any {
+	t.Helper()
+	for _, reply := range replies {
+		if reply["id"] == float64(id) {
+			return reply["result"]
+		}
+	}
+	t.Fatalf("no reply to %d in %v", id, replies)
+	return nil
+}
+
+func encode(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func diagnostics(replies []map[string]any) []any {
+	for _, reply := range replies {
+		if reply["method"] == "textDocument/publishDiagnostics" {
+			return reply["params"].(map[string]any)["diagnostics"].([]any)
+		}
+	}
+	return nil
+}
+
+const (
+	initializeRequest = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`
+	shutdown          = `{"jsonrpc":"2.0","id":99,"method":"shutdown"}`
+	exit              = `{"jsonrpc":"2.0","method":"exit"}`
+)
+
+func TestInitialize(t *testing.T) {
+	replies := session(t, initializeRequest, shutdown, exit)
+	capabilities := encode(result(t, replies, 0).(map[string]any)["capabilities"])
+	expected := `{"completionProvider":{},"definitionProvider":true,"documentSymbolProvider":true,"hoverProvider":true,"textDocumentSync":1}`
+	if capabilities != expected {
+		t.Errorf("got %s", capabilities)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"yen a = 1;\na sollu;\n", `[]`},
+		{"yen a = 1;\na + ;\n",
+			`[{"message":"No prefix handler for ;","range":{"end":{"character":5,"line":1},"start":{"character":4,"line":1}},"severity":1,"source":"niral"}]`},
+		{"sol s = \"தமிழ்\"; yen a = s + 1;",
+			`[{"message":"Cannot Assign STRING to INTEGER variable","range":{"end":{"character":30,"line":0},"start":{"character":25,"line":0}},"severity":1,"source":"niral"}]`},
+		{"b = 1;", `[{"message":"Assignment to undeclared variable b","range":{"end":{"character":1,"line":0},"start":{"character":0,"line":0}},"severity":2,"source":"niral"}]`},
+	}
+	for _, tt := range tests {
+		replies := session(t, initializeRequest, open(tt.text), shutdown, exit)
+		if got := encode(diagnostics(replies)); got != tt.expected {
+			t.Errorf("%q: got %s\nwant %s", tt.text, got, tt.expected)
+		}
+	}
+}
+
+const program = "என் எண் = 1;\nsol s kodu;\nஎண் = எண் + s neelam;\n"
+
+func TestHoverAndDefinition(t *testing.T) {
+	replies := session(t, initializeRequest, open(program),
+		at(1, "hover", 2, 7),
+		at(2, "definition", 2, 7),
+		at(3, "hover", 2, 13),
+		at(4, "hover", 0, 1),
+		shutdown, exit)
+	hover := result(t, replies, 1).(map[string]any)
+	if value := hover["contents"].(map[string]any)["value"]; value != "```niral\nஎன் எண்\n```\n\nINTEGER, declared on line 1" {
+		t.Errorf("hover %q", value)
+	}
+	if got := encode(hover["range"]); got != `{"end":{"character":9,"line":2},"start":{"character":6,"line":2}}` {
+		t.Errorf("hover range %s", got)
+	}
+	definition := encode(result(t, replies, 2))
+	if definition != `{"range":{"end":{"character":7,"line":0},"start":{"character":4,"line":0}},"uri":"file:///a.n"}` {
+		t.Errorf("definition %s", definition)
+	}
+	if value := result(t, replies, 3).(map[string]any)["contents"].(map[string]any)["value"]; value != "```niral\nsol s kodu\n```\n\nSTRING, declared on line 2" {
+		t.Errorf("hover %q", value)
+	}
+	if hover := result(t, replies, 4); hover != nil {
+		t.Errorf("hover on a keyword %v", hover)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	replies := session(t, initializeRequest, open(program), at(1, "completion", 3, 0), shutdown, exit)
+	labels := []string{}
+	for _, item := range result(t, replies, 1).([]any) {
+		labels = append(labels, item.(map[string]any)["label"].(string))
+	}
+	got := strings.Join(labels, " ")
+	for _, label := range []string{"endral", "என்றால்", "varaikkum", "வரைக்கும்", "எண்", "s"} {
+		if !strings.Contains(" "+got+" ", " "+label+" ") {
+			t.Errorf("missing %s in %s", label, got)
+		}
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	symbols := `{"jsonrpc":"2.0","id":1,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":"` + uri + `"}}}`
+	replies := session(t, initializeRequest, open(program), symbols, shutdown, exit)
+	names := []string{}
+	for _, symbol := range result(t, replies, 1).([]any) {
+		symbol := symbol.(map[string]any)
+		names = append(names, fmt.Sprintf("%s:%s", symbol["name"], symbol["detail"]))
+	}
+	if got := strings.Join(names, " "); got != "எண்:INTEGER s:STRING" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestSyntaxErrorKeepsSymbols(t *testing.T) {
+	change := `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"` + uri +
+		`","version":2},"contentChanges":[{"text":"என் எண் = 1;\nஎண் = "}]}}`
+	replies := session(t, initializeRequest, open(program), change, at(1, "definition", 0, 5), shutdown, exit)
+	if result(t, replies, 1) == nil {
+		t.Errorf("no definition after a syntax error")
+	}
+}
+
+func TestSyntaxErrorKeepsOffsets(t *testing.T) {
+	change := `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"` + uri +
+		`","version":2},"contentChanges":[{"text":"+"}]}}`
+	replies := session(t, initializeRequest, open(program), change, at(1, "definition", 2, 7), shutdown, exit)
+	definition := encode(result(t, replies, 1))
+	if definition != `{"range":{"end":{"character":7,"line":0},"start":{"character":4,"line":0}},"uri":"file:///a.n"}` {
+		t.Errorf("definition %s", definition)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	replies := session(t, initializeRequest, `{"jsonrpc":"2.0","id":1,"method":"textDocument/rename","params":{}}`, shutdown, exit)
+	for _, reply := range replies {
+		if reply["id"] == float64(1) {
+			if reply["error"].(map[string]any)["code"] != float64(methodNotFound) {
+				t.Errorf("got %v", reply)
+			}
+			return
+		}
+	}
+	t.Errorf("no reply")
+}
@@ -1,14 +1,31 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/iam-naveen/compiler/compiler"
+	"github.com/iam-naveen/compiler/coverage"
+	"github.com/iam-naveen/compiler/dap"
 	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/format"
 	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/lsp"
 	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/optimizer"
 	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tester"
+	"github.com/iam-naveen/compiler/trace"
+	"github.com/iam-naveen/compiler/tree"
+	"github.com/iam-naveen/compiler/vm"
 	"github.com/sanity-io/litter"
 )
 
@@ -17,29 +34,318 @@ func main() {
 		fmt.Println("Please provide the input file")
 		return
 	}
-	input, err := os.ReadFile(os.Args[1])
+	switch os.Args[1] {
+	case "parse":
+		parse(os.Args[2:])
+	case "fmt":
+		formatFiles(os.Args[2:])
+	case "test":
+		test(os.Args[2:])
+	case "dap":
+		if err := dap.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "lsp":
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		run(os.Args[1:])
+	}
+}
+
+// run executes the program in args[0], the rest of args are flags.
+func run(args []string) {
+	logging := slices.Contains(args, "-l") || slices.Contains(args, "--log")
+	lexLog := slices.Contains(args, "--lex-log")
+	ast, source, ok := readProgram(args[0], logging, lexLog)
+	if !ok {
+		return
+	}
+	if logging {
+		litter.Dump(ast)
+	}
+	if slices.Contains(args, "-O") || slices.Contains(args, "--optimize") {
+		optimize(ast)
+	}
+	if slices.Contains(args, "-t") || slices.Contains(args, "--tree") {
+		fmt.Println(ast.Print(0, "", ""))
+	}
+	tracing := slices.Contains(args, "--trace")
+	profiling := slices.Contains(args, "--profile") || option(args, "--profile", "") != ""
+	coverPath := option(args, "--coverage", "")
+	switch option(args, "--engine", "eval") {
+	case "vm":
+		if tracing || profiling || coverPath != "" {
+			fmt.Println("--trace, --profile and --coverage need --engine=eval")
+			os.Exit(1)
+		}
+		if slices.Contains(args, "--big") || option(args, "--seed", "") != "" || option(args, "--files", "") != "" || slices.Contains(args, "--retry-input") {
+			fmt.Println("--big, --seed, --files and --retry-input need --engine=eval")
+			os.Exit(1)
+		}
+		comp := compiler.New()
+		if err := comp.Compile(ast); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+		machine := vm.New(comp.Bytecode())
+		if err := machine.Run(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "eval":
+		interpreter := &evaluator.Interpreter{
+			Path:        args[0],
+			BigIntegers: slices.Contains(args, "--big"),
+			Files:       option(args, "--files", ""),
+			RetryInput:  slices.Contains(args, "--retry-input"),
+		}
+		if seed, ok := seedOption(args); ok {
+			interpreter.Rand = rand.New(rand.NewSource(seed))
+		}
+		hooks := evaluator.Hooks{}
+		if tracing {
+			hooks = append(hooks, trace.NewTracer(os.Stderr, args[0], source))
+		}
+		var profiler *trace.Profiler
+		if profiling {
+			profiler = trace.NewProfiler(args[0], source)
+			hooks = append(hooks, profiler)
+		}
+		var cover *coverage.Coverage
+		if coverPath != "" {
+			cover = coverage.New()
+			cover.Add(args[0], source, ast)
+			hooks = append(hooks, cover)
+		}
+		if len(hooks) > 0 {
+			interpreter.Hook = hooks
+		}
+		err := interpreter.Run(ast, object.NewEnvironment())
+		if profiler != nil {
+			writeProfile(profiler, option(args, "--profile", ""))
+		}
+		if cover != nil {
+			writeCoverage(cover, coverPath)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Println("Unknown engine, expected --engine=eval or --engine=vm")
+		os.Exit(1)
+	}
+}
+
+// writeProfile prints the profile table to stderr and writes the pprof
+// profile to path when one is given.
+func writeProfile(profiler *trace.Profiler, path string) {
+	profiler.WriteTable(os.Stderr)
+	if path == "" {
+		return
+	}
+	file, err := os.Create(path)
+	if err == nil {
+		err = profiler.WritePprof(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
-		fmt.Println("Error reading the file")
+		fmt.Fprintln(os.Stderr, "Error writing the profile:", err)
+		os.Exit(1)
+	}
+}
+
+// writeCoverage writes the coverage as LCOV to path and as HTML next to
+// it.
+func writeCoverage(cover *coverage.Coverage, path string) {
+	htmlPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".html"
+	for _, report := range []struct {
+		path  string
+		write func(io.Writer) error
+	}{{path, cover.WriteLCOV}, {htmlPath, cover.WriteHTML}} {
+		file, err := os.Create(report.path)
+		if err == nil {
+			err = report.write(file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing the coverage:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// test runs the test files found in the paths of args, the current
+// directory by default, and exits with 1 when one fails.
+func test(args []string) {
+	paths := files(args)
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	tests, err := tester.Discover(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(tests) == 0 {
+		fmt.Println("no test files")
 		return
 	}
-	logging := false
-	lexLog := false
-	if len(os.Args) > 2 {
-		if slices.Contains(os.Args, "-l") || slices.Contains(os.Args, "--log") {
-			logging = true
+	options := tester.Options{Update: slices.Contains(args, "--update"), Files: option(args, "--files", "")}
+	if seed, ok := seedOption(args); ok {
+		options.Seed = seed
+	}
+	coverPath := option(args, "--coverage", "")
+	if coverPath != "" {
+		options.Coverage = coverage.New()
+	}
+	results := []tester.Result{}
+	for _, path := range tests {
+		results = append(results, tester.Run(path, options))
+	}
+	passed := tester.Summary(os.Stdout, results)
+	if options.Coverage != nil {
+		writeCoverage(options.Coverage, coverPath)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// parse prints the tree of the program in args[0] without running it.
+func parse(args []string) {
+	paths := files(args)
+	if len(paths) < 1 {
+		fmt.Println("Please provide the input file")
+		return
+	}
+	ast, _, ok := readProgram(paths[0], false, false)
+	if !ok {
+		return
+	}
+	if slices.Contains(args, "-O") || slices.Contains(args, "--optimize") {
+		optimize(ast)
+	}
+	switch option(args, "--format", "tree") {
+	case "tree":
+		fmt.Println(ast.Print(0, "", ""))
+	case "json":
+		out, err := json.MarshalIndent(ast, "", "  ")
+		if err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
 		}
-		if slices.Contains(os.Args, "--lex-log") {
-			lexLog = true
+		fmt.Println(string(out))
+	default:
+		fmt.Println("Unknown format, expected --format=tree or --format=json")
+		os.Exit(1)
+	}
+}
+
+// formatFiles prints the files of args in the canonical style, or with
+// --write rewrites them in place, or with --check lists the files that
+// are not formatted and fails if there are any.
+func formatFiles(args []string) {
+	check := slices.Contains(args, "--check")
+	write := slices.Contains(args, "--write")
+	failed := false
+	for _, path := range files(args) {
+		input, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading the file", path)
+			failed = true
+			continue
 		}
+		out, err := format.Source(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			failed = true
+			continue
+		}
+		switch {
+		case check:
+			if !bytes.Equal(input, out) {
+				fmt.Println(path)
+				failed = true
+			}
+		case write:
+			if !bytes.Equal(input, out) {
+				if err := os.WriteFile(path, out, 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+					failed = true
+				}
+			}
+		default:
+			os.Stdout.Write(out)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readProgram(path string, logging, lexLog bool) (*tree.Program, []byte, bool) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Println("Error reading the file")
+		return nil, nil, false
 	}
 	_, channel := lexer.CreateLexer(input, lexLog)
-	ast := parser.Parse(channel, logging)
-	if logging {
-		litter.Dump(ast)
+	program, err := parser.TryParse(channel, logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", path, err)
+		os.Exit(1)
 	}
-	if slices.Contains(os.Args, "-t") || slices.Contains(os.Args, "--tree") {
-		fmt.Println(ast.Print(0, "", ""))
+	return program, input, true
+}
+
+func optimize(ast *tree.Program) {
+	for _, diagnostic := range optimizer.Optimize(ast) {
+		fmt.Fprintln(os.Stderr, diagnostic)
+	}
+}
+
+// files returns the arguments that are not flags.
+func files(args []string) []string {
+	paths := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			paths = append(paths, arg)
+		}
+	}
+	return paths
+}
+
+// seedOption returns the number of a --seed=n flag, exiting when it is
+// not an integer.
+func seedOption(args []string) (int64, bool) {
+	value := option(args, "--seed", "")
+	if value == "" {
+		return 0, false
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("--seed needs an integer, got %s\n", value)
+		os.Exit(1)
+	}
+	return seed, true
+}
+
+// option returns the value of a --name=value flag, or fallback when the
+// flag is not present.
+func option(args []string, name, fallback string) string {
+	for _, arg := range args {
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value
+		}
 	}
-	env := object.NewEnvironment()
-	evaluator.Eval(ast, env)
+	return fallback
 }
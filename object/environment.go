@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
@@ -29,3 +31,29 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign changes the variable in the innermost environment that has it,
+// or sets it in this one when none has.
+func (e *Environment) Assign(name string, val Object) Object {
+	for scope := e; scope != nil; scope = scope.outer {
+		if _, ok := scope.store[name]; ok {
+			return scope.Set(name, val)
+		}
+	}
+	return e.Set(name, val)
+}
+
+// Outer returns the enclosing environment, nil for the outermost one.
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// Names returns the names set in this environment, not the enclosing
+// ones, in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
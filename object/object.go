@@ -3,6 +3,9 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/iam-naveen/compiler/tree"
@@ -15,8 +18,12 @@ const (
 	ERROR_OBJ = "ERROR"
 
 	INTEGER_OBJ = "INTEGER"
+	FLOAT_OBJ   = "FLOAT"
 	STRING_OBJ  = "STRING"
 	BOOLEAN_OBJ = "BOOLEAN"
+	ARRAY_OBJ   = "ARRAY"
+	HASH_OBJ    = "HASH"
+	RANGE_OBJ   = "RANGE"
 
 	IDENTIFIER_OBJ = "IDENTIFIER"
 
@@ -37,12 +44,29 @@ type Integer struct {
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
+// BigInteger is an integer too large for an Integer, made by the
+// arithmetic of an interpreter in big integer mode. It has the type of
+// an Integer, so a program can not tell the two apart.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Type() ObjectType { return INTEGER_OBJ }
+func (b *BigInteger) Inspect() string  { return b.Value.String() }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string  { return tree.FormatFloat(f.Value) }
+
 type String struct {
 	Value string
 }
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
-func (s *String) Inspect() string { return s.Value }
+func (s *String) Inspect() string  { return s.Value }
 
 type Boolean struct {
 	Value bool
@@ -51,6 +75,62 @@ type Boolean struct {
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
 
+// Array is a list of values, strings among them are written quoted.
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	elements := []string{}
+	for _, element := range a.Elements {
+		if element.Type() == STRING_OBJ {
+			elements = append(elements, strconv.Quote(element.Inspect()))
+		} else {
+			elements = append(elements, element.Inspect())
+		}
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// Hash maps strings to values, it is written with its keys sorted so the
+// same hash always reads the same.
+type Hash struct {
+	Pairs map[string]Object
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	pairs := []string{}
+	for _, key := range h.Keys() {
+		value := h.Pairs[key]
+		if value.Type() == STRING_OBJ {
+			pairs = append(pairs, strconv.Quote(key)+": "+strconv.Quote(value.Inspect()))
+		} else {
+			pairs = append(pairs, strconv.Quote(key)+": "+value.Inspect())
+		}
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Keys returns the keys of the hash in sorted order.
+func (h *Hash) Keys() []string {
+	keys := make([]string, 0, len(h.Pairs))
+	for key := range h.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Range is the integers from Start up to, not including, End.
+type Range struct {
+	Start, End int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string  { return fmt.Sprintf("range(%d, %d)", r.Start, r.End) }
+
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
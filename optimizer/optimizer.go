@@ -0,0 +1,232 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Diagnostic reports an expression that could not be folded because it
+// would fail when evaluated.
+type Diagnostic struct {
+	Piece   lexer.Piece // operator of the offending expression
+	Message string
+	Expr    tree.Expr
+}
+
+func (d Diagnostic) String() string {
+	if d.Piece.Pos.IsValid() {
+		return fmt.Sprintf("%s: warning: %s in %v", d.Piece.Pos, d.Message, d.Expr)
+	}
+	return fmt.Sprintf("warning: %s in %v", d.Message, d.Expr)
+}
+
+type optimizer struct {
+	diagnostics []Diagnostic
+}
+
+// Optimize rewrites the program in place, folding constant expressions
+// and removing branches whose condition is a literal.
+func Optimize(program *tree.Program) []Diagnostic {
+	o := &optimizer{}
+	tree.Rewrite(program, o.rewrite)
+	return o.diagnostics
+}
+
+// rewrite returns the replacement for node, nil when it can be removed.
+func (o *optimizer) rewrite(node tree.Node) tree.Node {
+	switch node := node.(type) {
+	case *tree.Program:
+		node.Statements = splice(node.Statements)
+	case *tree.Block:
+		node.Statements = splice(node.Statements)
+	case *tree.Prefix:
+		return o.foldPrefix(node)
+	case *tree.Binary:
+		return o.foldBinary(node)
+	case *tree.IfStmt:
+		return foldIf(node)
+	case *tree.WhileStmt:
+		if condition, ok := node.Condition.(*tree.Boolean); ok && !condition.Value {
+			return nil
+		}
+	case *tree.ForStmt:
+		if count, ok := node.Count.(*tree.Number); ok && count.Value <= 0 {
+			return nil
+		}
+	}
+	return node
+}
+
+// foldIf drops the else ifs whose condition is false, makes the first
+// one that is true the else block, and replaces the statement by the
+// block that always runs when its own condition is a literal.
+func foldIf(stmt *tree.IfStmt) tree.Node {
+	elseIfs := []*tree.ElseIf{}
+	for _, elseIf := range stmt.ElseIfs {
+		condition, ok := elseIf.Condition.(*tree.Boolean)
+		if !ok {
+			elseIfs = append(elseIfs, elseIf)
+			continue
+		}
+		if condition.Value {
+			stmt.Else = elseIf.Then
+			break
+		}
+	}
+	stmt.ElseIfs = elseIfs
+	condition, ok := stmt.Condition.(*tree.Boolean)
+	switch {
+	case !ok:
+		return stmt
+	case condition.Value:
+		return stmt.Then
+	case len(elseIfs) > 0:
+		first := elseIfs[0]
+		return &tree.IfStmt{Piece: first.Piece, Condition: first.Condition, Then: first.Then, ElseIfs: elseIfs[1:], Else: stmt.Else}
+	case stmt.Else == nil:
+		return nil
+	}
+	return stmt.Else
+}
+
+// splice inlines the blocks left behind by removed branches. The language
+// has a single scope, so this does not change the meaning of the program.
+func splice(stmts []tree.Stmt) []tree.Stmt {
+	out := []tree.Stmt{}
+	for _, stmt := range stmts {
+		if block, ok := stmt.(*tree.Block); ok {
+			out = append(out, block.Statements...)
+		} else {
+			out = append(out, stmt)
+		}
+	}
+	return out
+}
+
+func (o *optimizer) foldPrefix(expr *tree.Prefix) tree.Expr {
+	right, ok := constant(expr.Right)
+	if !ok {
+		return expr
+	}
+	result := evaluator.EvalPrefix(expr.Operator, right)
+	if err, isErr := result.(*object.Error); isErr {
+		o.report(expr.Operator, err.Message, expr)
+		return expr
+	}
+	if !written(result) {
+		return expr
+	}
+	return literal(result, tree.SpanOf(expr))
+}
+
+func (o *optimizer) foldBinary(expr *tree.Binary) tree.Expr {
+	left, ok := constant(expr.Left)
+	if !ok {
+		return expr
+	}
+	right, ok := constant(expr.Right)
+	if !ok {
+		return expr
+	}
+	if msg, ok := checkOperands(expr.Operator.Kind, left, right); !ok {
+		o.report(expr.Operator, msg, expr)
+		return expr
+	}
+	result := evaluator.EvalBinary(expr.Operator, left, right)
+	if err, isErr := result.(*object.Error); isErr {
+		o.report(expr.Operator, err.Message, expr)
+		return expr
+	}
+	if !written(result) {
+		return expr
+	}
+	return literal(result, tree.SpanOf(expr))
+}
+
+// checkOperands rejects the operand combinations that would crash the
+// evaluator instead of producing an error value.
+func checkOperands(kind lexer.PieceType, left, right object.Object) (string, bool) {
+	mismatch := fmt.Sprintf("Invalid Operand Types %s and %s", left.Type(), right.Type())
+	switch kind {
+	case lexer.Plus:
+		if left.Type() == object.BOOLEAN_OBJ {
+			return "", true // reported by the evaluator as an Unknown Type
+		}
+		if right.Type() == object.BOOLEAN_OBJ {
+			return mismatch, false
+		}
+	case lexer.Slash, lexer.Percent:
+		if kind == lexer.Percent && (!numeric(left) || !numeric(right)) {
+			return mismatch, false
+		}
+	case lexer.Less, lexer.Greater, lexer.LessEqual, lexer.GreaterEqual:
+		if !numeric(left) || !numeric(right) {
+			return mismatch, false
+		}
+	case lexer.And, lexer.Or:
+		if left.Type() != object.BOOLEAN_OBJ || right.Type() != object.BOOLEAN_OBJ {
+			return mismatch, false
+		}
+	}
+	return "", true
+}
+
+func (o *optimizer) report(piece lexer.Piece, msg string, expr tree.Expr) {
+	o.diagnostics = append(o.diagnostics, Diagnostic{Piece: piece, Message: msg, Expr: expr})
+}
+
+func numeric(value object.Object) bool {
+	return value.Type() == object.INTEGER_OBJ || value.Type() == object.FLOAT_OBJ
+}
+
+// constant converts a literal node to the object it evaluates to.
+func constant(expr tree.Expr) (object.Object, bool) {
+	switch expr := expr.(type) {
+	case *tree.Number:
+		return &object.Integer{Value: expr.Value}, true
+	case *tree.Float:
+		return &object.Float{Value: expr.Value}, true
+	case *tree.StringLiteral:
+		return &object.String{Value: expr.Value}, true
+	case *tree.Boolean:
+		return &object.Boolean{Value: expr.Value}, true
+	}
+	return nil, false
+}
+
+// written reports whether a literal can be written for the value, an
+// infinite float can not.
+func written(value object.Object) bool {
+	f, ok := value.(*object.Float)
+	return !ok || !(math.IsInf(f.Value, 0) || math.IsNaN(f.Value))
+}
+
+// literal converts a folded value back to a node covering the span of
+// the expression it replaces.
+func literal(obj object.Object, span tree.Span) tree.Expr {
+	piece := lexer.Piece{Pos: span.Start, End: span.End}
+	switch obj := obj.(type) {
+	case *object.Integer:
+		piece.Kind, piece.Value = lexer.Number, strconv.FormatInt(obj.Value, 10)
+		return &tree.Number{Piece: piece, Value: obj.Value}
+	case *object.Float:
+		piece.Kind, piece.Value = lexer.Number, tree.FormatFloat(obj.Value)
+		return &tree.Float{Piece: piece, Value: obj.Value}
+	case *object.String:
+		piece.Kind, piece.Value = lexer.StringLiteral, obj.Value
+		return &tree.StringLiteral{Piece: piece, Value: obj.Value}
+	case *object.Boolean:
+		piece.Kind, piece.Value = lexer.Boolean, "illai"
+		if obj.Value {
+			piece.Value = "aam"
+		}
+		return &tree.Boolean{Piece: piece, Value: obj.Value}
+	}
+	panic("cannot convert " + string(obj.Type()) + " to a literal")
+}
@@ -0,0 +1,104 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+func parse(input string) *tree.Program {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return parser.Parse(channel, false)
+}
+
+func TestFolding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected tree.Expr
+	}{
+		{`yen a = 60 * 60 * 24;`, &tree.Number{Value: 86400}},
+		{`yen a = 7 % 4 + -2;`, &tree.Number{Value: 1}},
+		{`sol a = "x" + 1 + "y";`, &tree.StringLiteral{Value: "x1y"}},
+		{`yen a = 1 < 2 && !illai;`, &tree.Boolean{Value: true}},
+		{`yen a = "a" == "b";`, &tree.Boolean{Value: false}},
+	}
+	for _, tt := range tests {
+		program := parse(tt.input)
+		if diagnostics := Optimize(program); len(diagnostics) != 0 {
+			t.Errorf("%s: unexpected diagnostics %v", tt.input, diagnostics)
+		}
+		value := program.Statements[0].(*tree.Declaration).Value
+		if value.String() != tt.expected.String() {
+			t.Errorf("%s: folded to %v, want %v", tt.input, value, tt.expected)
+		}
+	}
+}
+
+func TestPartialFolding(t *testing.T) {
+	program := parse(`yen a = b + 2 * 3;`)
+	Optimize(program)
+	value := program.Statements[0].(*tree.Declaration).Value
+	if value.String() != "(b + 6)" {
+		t.Errorf("folded to %v, want (b + 6)", value)
+	}
+}
+
+func TestDeadBranches(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`aam endral { "yes" sollu; } illana { "no" sollu; }`, []string{"print yes\n"}},
+		{`1 > 2 endral { "yes" sollu; } illana { "no" sollu; }`, []string{"print no\n"}},
+		{`illai endral { "yes" sollu; } "after" sollu;`, []string{"print after\n"}},
+		{`illai endral { "a" sollu; } illana aam endral { "b" sollu; } illana { "c" sollu; }`, []string{"print b\n"}},
+		{`illai endral { "a" sollu; } illana illai endral { "b" sollu; }`, nil},
+		{`yen a = 1; illai endral { } illana a > 0 endral { "b" sollu; } illana 1 > 2 endral { "c" sollu; } illana { "d" sollu; }`,
+			[]string{"INTEGER a 1\n", "if (a > 0) print b\n\nelse print d\n"}},
+		{`yen a = 1; a > 0 endral { "a" sollu; } illana 2 > 1 endral { "b" sollu; } illana a < 0 endral { "c" sollu; }`,
+			[]string{"INTEGER a 1\n", "if (a > 0) print a\n\nelse print b\n"}},
+		{`illai varaikkum { "loop" sollu; } "after" sollu;`, []string{"print after\n"}},
+		{`1 - 1 murai { "loop" sollu; } "after" sollu;`, []string{"print after\n"}},
+	}
+	for _, tt := range tests {
+		program := parse(tt.input)
+		Optimize(program)
+		if len(program.Statements) != len(tt.expected) {
+			t.Fatalf("%s: got %d statements, want %d", tt.input, len(program.Statements), len(tt.expected))
+		}
+		for i, stmt := range program.Statements {
+			if stmt.String() != tt.expected[i] {
+				t.Errorf("%s: statement %d is %q, want %q", tt.input, i, stmt, tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{`yen a = 1 / 0;`, "Division by zero"},
+		{`yen a = 5 % (2 - 2);`, "Division by zero"},
+		{`yen a = "x" - 1;`, "Type Mismatch: Cannot perform operation with STRING and INTEGER"},
+		{`yen a = "x" < 1;`, "Invalid Operand Types STRING and INTEGER"},
+		{`yen a = -"x";`, "Invalid Operand Type"},
+	}
+	for _, tt := range tests {
+		program := parse(tt.input)
+		diagnostics := Optimize(program)
+		if len(diagnostics) != 1 {
+			t.Fatalf("%s: got %d diagnostics, want 1", tt.input, len(diagnostics))
+		}
+		if diagnostics[0].Message != tt.message {
+			t.Errorf("%s: got %q, want %q", tt.input, diagnostics[0].Message, tt.message)
+		}
+		value := program.Statements[0].(*tree.Declaration).Value
+		if _, folded := constant(value); folded {
+			t.Errorf("%s: erroneous expression was folded to %v", tt.input, value)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Error is a syntax error found at a piece of the source.
+type Error struct {
+	Piece   lexer.Piece
+	Message string
+}
+
+func (e *Error) Error() string {
+	if !e.Piece.Pos.IsValid() {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Piece.Pos, e.Message)
+}
+
+// fail stops the parse with a syntax error at the current piece.
+func (p *Parser) fail(format string, args ...any) {
+	panic(&Error{Piece: *p.piece, Message: fmt.Sprintf(format, args...)})
+}
+
+// TryParse parses like Parse but returns the syntax error instead of
+// panicking with it.
+func TryParse(channel chan lexer.Piece, logging bool) (program *tree.Program, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			syntax, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			// let the lexer finish instead of blocking on a send forever
+			go func() {
+				for range channel {
+				}
+			}()
+			program, err = nil, syntax
+		}
+	}()
+	return Parse(channel, logging), nil
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+)
+
+func TestSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"yen a = 1;\na + ;", "2:5: No prefix handler for ;"},
+		{"yen 1 = 2;", "1:5: Expected identifier got 1"},
+		{"yen a + 1;", "1:7: Expected '=', ';' or 'kodu' after a"},
+		{"yen a kodu 1;", "1:12: Expected ;"},
+		{"a = 1 2;", "1:7: Expected ;"},
+		{"aam endral 1;", "1:12: Expected '{'"},
+		{"aam varaikkum ;", "1:15: Expected '{' after 'varaikkum'"},
+		// an illana without a block is an else if or a single statement
+		{"aam endral { } illana \"no\" 1;", "1:28: Expected ;"},
+		{"aam endral { } illana \"no\" sollu", "1:33: Expected ;"},
+		{"aam endral { } illana illai endral 1;", "1:36: Expected '{'"},
+		{"aam endral { } illana illai endral { } illana ;", "1:47: No prefix handler for ;"},
+		{"yen a = (1;", "1:11: Expected closing paranthesis"},
+		{"aam endral {", "1:13: Unexpected end of file"},
+		{"+ 1;", "1:1: No statement handler for +"},
+		{"என் எண் = ;", "1:11: No prefix handler for ;"},
+		{"assert_true(aam;", "1:16: Expected ',' or ')' after the arguments of assert_true"},
+		{"yen a = 1(2);", "1:10: Only functions can be called"},
+		{"varisai a = [1 2];", "1:16: Expected ',' or ']' after an element of the array"},
+		{"a irakkumathi;", "1:3: Expected a file name before 'irakkumathi'"},
+		{"\"a.n\" irakkumathi", "1:18: Expected ;"},
+		{"1, 2;", "1:5: Expected 'sollu' or 'solli' after a list of values"},
+		{"1, sollu;", "1:4: No prefix handler for sollu"},
+		{"niruthu;", "1:1: 'niruthu' can only be used inside a loop"},
+		{"aam endral { தொடர்; }", "1:14: 'தொடர்' can only be used inside a loop"},
+		{"1 murai { } thodar;", "1:13: 'thodar' can only be used inside a loop"},
+		{"aam varaikkum { niruthu 1; }", "1:25: Expected ;"},
+		{"xs ovvonrum { }", "1:13: Expected a name after 'ovvonrum' got {"},
+		{"muyarchi;", "1:9: Expected '{' after 'muyarchi'"},
+		{"muyarchi { } 1 sollu;", "1:14: Expected 'pidi' after the block of 'muyarchi'"},
+		{"muyarchi { } pidi { }", "1:19: Expected a name after 'pidi' got {"},
+		{"muyarchi { } pidi e;", "1:20: Expected '{' after the name of 'pidi'"},
+		{"muyarchi { } pidi e { } iruthiyaga;", "1:35: Expected '{' after 'iruthiyaga'"},
+		{"pidi e { }", "1:1: No statement handler for pidi"},
+		{"\"x\" eri 1;", "1:9: Expected ;"},
+		{"xs ovvonrum i, { }", "1:16: Expected a name after 'ovvonrum' got {"},
+		{"xs ovvonrum i, x, y { }", "1:17: Expected '{' after the names of 'ovvonrum'"},
+		{"xs ovvonrum x;", "1:14: Expected '{' after the names of 'ovvonrum'"},
+		{"xs ovvonrum x { niruthu; } thodar;", "1:28: 'thodar' can only be used inside a loop"},
+	}
+	for _, tt := range tests {
+		_, channel := lexer.CreateLexer([]byte(tt.input), false)
+		_, err := TryParse(channel, false)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%q: got %v, want %s", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestTamilKeywords(t *testing.T) {
+	_, channel := lexer.CreateLexer([]byte("என் a = 1; சொல் s kodu; ஆம் என்றால் { a சொல்லு; } இல்லனா { s நீளம் சொல்லு; }"), false)
+	program, err := TryParse(channel, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "INTEGER a 1\nget {identifier: s s}if true print a\n\nelse print length s\n"
+	if program.String() != expected {
+		t.Errorf("got %q", program.String())
+	}
+}
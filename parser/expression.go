@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/iam-naveen/compiler/lexer"
 	"github.com/iam-naveen/compiler/tree"
@@ -10,8 +11,11 @@ import (
 
 func (p *Parser) parseExpression(pre precedence) tree.Expr {
 	prefix, ok := prefixHandlers[p.piece.Kind]
+	if !ok && p.piece.Kind == lexer.Eof {
+		p.fail("Unexpected end of file")
+	}
 	if !ok {
-		panic("No prefix handler for " + p.piece.Value)
+		p.fail("No prefix handler for %s", p.piece.Value)
 	}
 	left := prefix(p)
 	for p.piece.Kind != lexer.Eol && pre < precLookup[p.piece.Kind] {
@@ -34,10 +38,24 @@ func parseIdentifier(p *Parser) tree.Expr {
 }
 
 func parseNumber(p *Parser) tree.Expr {
+	if strings.Contains(p.piece.Value, ".") {
+		return parseFloat(p)
+	}
 	number := &tree.Number{Piece: *p.piece}
 	val, err := strconv.ParseInt(p.piece.Value, 10, 64)
 	if err != nil {
-		panic(err)
+		p.fail("Invalid number %s", p.piece.Value)
+	}
+	number.Value = val
+	p.move()
+	return number
+}
+
+func parseFloat(p *Parser) tree.Expr {
+	number := &tree.Float{Piece: *p.piece}
+	val, err := strconv.ParseFloat(p.piece.Value, 64)
+	if err != nil {
+		p.fail("Invalid number %s", p.piece.Value)
 	}
 	number.Value = val
 	p.move()
@@ -57,13 +75,13 @@ func parseBoolean(p *Parser) tree.Expr {
 	boolean := &tree.Boolean{
 		Piece: *p.piece,
 	}
-	switch p.piece.Value {
+	switch lexer.Tanglish(p.piece.Value) {
 	case "aam":
 		boolean.Value = true
 	case "illai":
 		boolean.Value = false
 	default:
-		panic("Invalid boolean value")
+		p.fail("Invalid boolean value")
 	}
 	p.move()
 	return boolean
@@ -73,7 +91,7 @@ func parseGrouped(p *Parser) tree.Expr {
 	p.move()
 	expr := p.parseExpression(LOWEST)
 	if p.piece.Kind != lexer.ParanClose {
-		panic("Expected closing paranthesis")
+		p.fail("Expected closing paranthesis")
 	}
 	p.move()
 	return expr
@@ -100,7 +118,7 @@ func parseInfix(p *Parser, left tree.Expr, bp precedence) tree.Expr {
 				Right: right,
 			}
 		}
-		panic("Left hand side of assignment must be an identifier")
+		p.fail("Left hand side of assignment must be an identifier")
 	}
 	return &tree.Binary{
 		Left:     left,
@@ -117,8 +135,9 @@ func parseIndex(p *Parser, left tree.Expr, _ precedence) tree.Expr {
 	p.move()
 	index.Index = p.parseExpression(LOWEST)
 	if p.piece.Kind != lexer.BracketClose {
-		panic("Expected closing bracket")
+		p.fail("Expected closing bracket")
 	}
+	index.Close = *p.piece
 	p.move()
 	return index
 }
@@ -126,12 +145,52 @@ func parseIndex(p *Parser, left tree.Expr, _ precedence) tree.Expr {
 func parseCall(p *Parser, left tree.Expr, _ precedence) tree.Expr {
 	call := &tree.Length{
 		Piece: *p.piece,
-		Value:  left,
+		Value: left,
+	}
+	p.move()
+	return call
+}
+
+func parseFunctionCall(p *Parser, left tree.Expr, _ precedence) tree.Expr {
+	name, ok := left.(*tree.Identifier)
+	if !ok {
+		p.fail("Only functions can be called")
+	}
+	call := &tree.Call{Function: *name}
+	p.move()
+	for p.piece.Kind != lexer.ParanClose {
+		call.Args = append(call.Args, p.parseExpression(LOWEST))
+		switch p.piece.Kind {
+		case lexer.Comma:
+			p.move()
+		case lexer.ParanClose:
+		default:
+			p.fail("Expected ',' or ')' after the arguments of %s", name.Name)
+		}
 	}
+	call.Close = *p.piece
 	p.move()
 	return call
 }
 
+func parseArray(p *Parser) tree.Expr {
+	array := &tree.Array{Piece: *p.piece}
+	p.move()
+	for p.piece.Kind != lexer.BracketClose {
+		array.Elements = append(array.Elements, p.parseExpression(LOWEST))
+		switch p.piece.Kind {
+		case lexer.Comma:
+			p.move()
+		case lexer.BracketClose:
+		default:
+			p.fail("Expected ',' or ']' after an element of the array")
+		}
+	}
+	array.Close = *p.piece
+	p.move()
+	return array
+}
+
 func parsePrint(p *Parser, left tree.Expr, _ precedence) tree.Expr {
 	printExpr := &tree.Print{Piece: *p.piece}
 	printExpr.Value = left
@@ -156,22 +215,23 @@ func parseIf(p *Parser, left tree.Expr, _ precedence) tree.Expr {
 
 func parseBlockStatement(p *Parser) *tree.Block {
 	if p.piece.Kind != lexer.BraceOpen {
-		panic("Expected opening curly brace")
+		p.fail("Expected opening curly brace")
 	}
 	block := &tree.Block{
-		Piece:    *p.piece,
-		Children: []tree.Stmt{},
+		Piece:      *p.piece,
+		Statements: []tree.Stmt{},
 	}
 	p.move()
 	for p.piece.Kind != lexer.BraceClose {
 		stmt := parseStatement(p)
 		if stmt != nil {
-			block.Children = append(block.Children, stmt)
+			block.Statements = append(block.Statements, stmt)
 		}
 		if p.piece.Kind == lexer.BraceClose {
 			break
 		}
 	}
+	block.Close = *p.piece
 	p.move()
 	return block
 }
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+)
+
+// FuzzParser checks that any input either parses or fails with a syntax
+// error, TryParse lets every other panic through.
+func FuzzParser(f *testing.F) {
+	programs, err := filepath.Glob(filepath.Join("..", "testdata", "*.n"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range programs {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(source)
+	}
+	f.Fuzz(func(t *testing.T, source []byte) {
+		_, channel := lexer.CreateLexer(source, false)
+		program, err := TryParse(channel, false)
+		if err == nil {
+			program.Print(0, "", "")
+		}
+	})
+}
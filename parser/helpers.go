@@ -1,11 +1,24 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/tree"
+)
 
 func (p *Parser) move() {
 	for {
 		select {
 		case piece := <-p.channel:
+			if piece.Kind == lexer.Comment {
+				p.comments = append(p.comments, &tree.Comment{
+					Piece: piece,
+					Text:  strings.TrimRight(piece.Value, "\r"),
+				})
+				continue
+			}
 			p.prev = p.piece
 			p.piece = &piece
 			if p.logEnabled {
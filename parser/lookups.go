@@ -64,6 +64,10 @@ func (p *Parser) createHandlers() {
 	setStmtHandler(lexer.Number, parseStatement)
 	setStmtHandler(lexer.Boolean, parseStatement)
 	setStmtHandler(lexer.StringLiteral, parseStatement)
+	setStmtHandler(lexer.BracketOpen, parseStatement)
+	setStmtHandler(lexer.Break, parseStatement)
+	setStmtHandler(lexer.Continue, parseStatement)
+	setStmtHandler(lexer.Try, parseStatement)
 
 	setPrefixHandler(lexer.Identifier, parseIdentifier)
 	setPrefixHandler(lexer.Number, parseNumber)
@@ -72,7 +76,8 @@ func (p *Parser) createHandlers() {
 	setPrefixHandler(lexer.Minus, parsePrefix)
 	setPrefixHandler(lexer.Bang, parsePrefix)
 	setPrefixHandler(lexer.ParanOpen, parseGrouped)
-	
+	setPrefixHandler(lexer.BracketOpen, parseArray)
+
 	setInfixHandler(lexer.Plus, ADDITIVE, parseInfix)
 	setInfixHandler(lexer.Minus, ADDITIVE, parseInfix)
 	setInfixHandler(lexer.Star, MULTIPLICATIVE, parseInfix)
@@ -89,4 +94,5 @@ func (p *Parser) createHandlers() {
 	setInfixHandler(lexer.Or, LOGICAL, parseInfix)
 	setInfixHandler(lexer.BracketOpen, MEMBER, parseIndex)
 	setInfixHandler(lexer.Length, CALL, parseCall)
+	setInfixHandler(lexer.ParanOpen, CALL, parseFunctionCall)
 }
@@ -13,6 +13,8 @@ type Parser struct {
 	prev       *lexer.Piece
 	channel    chan lexer.Piece
 	logEnabled bool
+	comments   []*tree.Comment
+	loops      int // loops around the statement being parsed
 }
 
 func (p Parser) String() string {
@@ -35,15 +37,18 @@ func Parse(channel chan lexer.Piece, logging bool) *tree.Program {
 	for present {
 		stmt := handler(parser)
 		if stmt != nil {
-			program.Children = append(program.Children, stmt)
+			program.Statements = append(program.Statements, stmt)
 		} else {
-			fmt.Println("Error parsing statement", parser)
-			break
+			parser.fail("Error parsing statement")
 		}
 		if parser.piece.Kind == lexer.Eof {
 			break
 		}
 		handler, present = stmtHandlers[parser.piece.Kind]
 	}
+	if !present && parser.piece.Kind != lexer.Eof {
+		parser.fail("No statement handler for %s", parser.piece.Value)
+	}
+	program.Comments = parser.comments
 	return program
 }
@@ -2,7 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/iam-naveen/compiler/lexer"
 	"github.com/iam-naveen/compiler/tree"
@@ -12,6 +11,10 @@ func parseStatement(p *Parser) tree.Stmt {
 	switch p.piece.Kind {
 	case lexer.DataType:
 		return p.parseDeclarationStatement()
+	case lexer.Break, lexer.Continue:
+		return p.parseBranchStatement()
+	case lexer.Try:
+		return p.parseTryStatement()
 	default:
 		return p.parserExpressionStatement()
 	}
@@ -20,21 +23,28 @@ func parseStatement(p *Parser) tree.Stmt {
 func (p *Parser) parseDeclarationStatement() tree.Stmt {
 	var datatype string
 	var name lexer.Piece
-	if p.piece.Value == "yen" {
+	keyword := *p.piece
+	if lexer.Tanglish(p.piece.Value) == "yen" {
 		datatype = "INTEGER"
-	} else if p.piece.Value == "sol" {
+	} else if lexer.Tanglish(p.piece.Value) == "sol" {
 		datatype = "STRING"
+	} else if lexer.Tanglish(p.piece.Value) == "varisai" {
+		datatype = "ARRAY"
+	} else if lexer.Tanglish(p.piece.Value) == "thasamam" {
+		datatype = "FLOAT"
+	} else if lexer.Tanglish(p.piece.Value) == "agarathi" {
+		datatype = "HASH"
 	}
 	p.move()
 	if p.piece.Kind != lexer.Identifier {
-		fmt.Println("Expected identifier got " + p.piece.Value)
-		os.Exit(1)
+		p.fail("Expected identifier got %s", p.piece.Value)
 	}
 	name = *p.piece
 	p.move()
 	switch p.piece.Kind {
 	case lexer.Eol:
 		stmt := &tree.Declaration{
+			Piece:    keyword,
 			Datatype: datatype,
 			Name:     name,
 		}
@@ -45,10 +55,16 @@ func (p *Parser) parseDeclarationStatement() tree.Stmt {
 		return stmt
 	case lexer.Assign:
 		p.move()
+		value := p.parseExpression(LOWEST)
+		if p.piece.Kind == lexer.Input {
+			// the value is the prompt of the input
+			return p.parseInputStatement(name, datatype, value)
+		}
 		stmt := &tree.Declaration{
+			Piece:    keyword,
 			Datatype: datatype,
 			Name:     name,
-			Value:    p.parseExpression(LOWEST),
+			Value:    value,
 		}
 		if p.logEnabled {
 			fmt.Println("Declaration statement parsed\n\t", stmt)
@@ -65,28 +81,41 @@ func (p *Parser) parseDeclarationStatement() tree.Stmt {
 	// 	return nil
 
 	case lexer.Input:
-		inputStmt := &tree.Input{
-			Piece: *p.piece,
-			Variable: tree.Identifier{
-				Piece: *p.prev,
-				Name:  p.prev.Value,
-			},
-			DataType: datatype,
-		}
-		p.move()
-		if p.piece.Kind != lexer.Eol {
-			fmt.Println("Expected ;")
-			os.Exit(1)
-		}
-		p.move()
-		return inputStmt
+		return p.parseInputStatement(name, datatype, nil)
 	default:
+		p.fail("Expected '=', ';' or 'kodu' after %s", name.Value)
 		return nil
 	}
 }
 
+func (p *Parser) parseInputStatement(name lexer.Piece, datatype string, prompt tree.Expr) tree.Stmt {
+	inputStmt := &tree.Input{
+		Piece: *p.piece,
+		Variable: tree.Identifier{
+			Piece: name,
+			Name:  name.Value,
+		},
+		DataType: datatype,
+		Prompt:   prompt,
+	}
+	p.move()
+	if p.piece.Kind != lexer.Eol {
+		p.fail("Expected ;")
+	}
+	p.move()
+	return inputStmt
+}
+
 func (p *Parser) parserExpressionStatement() tree.Stmt {
-	expr := p.parseExpression(LOWEST) // After parsing, we are at the next token
+	return p.statementAfter(p.parseExpression(LOWEST)) // After parsing, we are at the next token
+}
+
+// statementAfter parses the rest of a statement starting with expr, by
+// the keyword following it.
+func (p *Parser) statementAfter(expr tree.Expr) tree.Stmt {
+	if p.piece.Kind == lexer.Comma {
+		return p.parsePrintList(expr)
+	}
 	switch p.piece.Kind {
 	case lexer.If:
 		return p.parseIfStatement(expr)
@@ -94,29 +123,68 @@ func (p *Parser) parserExpressionStatement() tree.Stmt {
 		return p.parseWhileStatement(expr)
 	case lexer.For:
 		return p.parseForStatement(expr)
+	case lexer.Each:
+		return p.parseEachStatement(expr)
 	case lexer.Print:
-		printStmt := &tree.PrintStmt{Piece: *p.piece}
-		printStmt.Value = expr
-		p.move()
+		return p.parsePrintStatement([]tree.Expr{expr})
+	case lexer.Raise:
+		return p.parseRaiseStatement(expr)
+	case lexer.Import:
+		return p.parseImportStatement(expr)
+	default:
 		if p.piece.Kind != lexer.Eol {
-			panic("Expected ;")
+			p.fail("Expected ;")
 		}
-		p.move()
-		return printStmt
-	default:
 		p.move()
 		return &tree.ExpressionStmt{Expression: expr}
 	}
 }
 
+// parsePrintList parses the values after the first one of a print,
+// each following a comma.
+func (p *Parser) parsePrintList(first tree.Expr) tree.Stmt {
+	values := []tree.Expr{first}
+	for p.piece.Kind == lexer.Comma {
+		p.move()
+		values = append(values, p.parseExpression(LOWEST))
+	}
+	if p.piece.Kind != lexer.Print {
+		p.fail("Expected 'sollu' or 'solli' after a list of values")
+	}
+	return p.parsePrintStatement(values)
+}
+
+func (p *Parser) parsePrintStatement(values []tree.Expr) tree.Stmt {
+	printStmt := &tree.PrintStmt{Piece: *p.piece, Values: values}
+	p.move()
+	if p.piece.Kind != lexer.Eol {
+		p.fail("Expected ;")
+	}
+	p.move()
+	return printStmt
+}
+
+func (p *Parser) parseImportStatement(expr tree.Expr) tree.Stmt {
+	path, ok := expr.(*tree.StringLiteral)
+	if !ok {
+		p.fail("Expected a file name before 'irakkumathi'")
+	}
+	importStmt := &tree.ImportStmt{Piece: *p.piece, Path: *path}
+	p.move()
+	if p.piece.Kind != lexer.Eol {
+		p.fail("Expected ;")
+	}
+	p.move()
+	return importStmt
+}
+
 func (p *Parser) parseWhileStatement(expr tree.Expr) tree.Stmt {
-	whileStmt := &tree.WhileStmt{Condition: expr}
+	whileStmt := &tree.WhileStmt{Piece: *p.piece, Condition: expr}
 	p.move()
 	if p.piece.Kind != lexer.BraceOpen {
-		fmt.Println("Expected '{' after 'varaikkum'")
-		os.Exit(1)
+		p.fail("Expected '{' after 'varaikkum'")
 	}
-	whileStmt.Body = p.parseBlockStatement()
+	whileStmt.Body = p.parseLoopBody()
 	return whileStmt
 }
 
@@ -124,43 +192,155 @@ func (p *Parser) parseForStatement(expr tree.Expr) tree.Stmt {
 	forStmt := &tree.ForStmt{Piece: *p.piece, Count: expr}
 	p.move()
 	if p.piece.Kind != lexer.BraceOpen {
-		fmt.Println("Expected '{' after 'murai'")
-		os.Exit(1)
+		p.fail("Expected '{' after 'murai'")
 	}
-	forStmt.Body = p.parseBlockStatement()
+	forStmt.Body = p.parseLoopBody()
 	return forStmt
 }
 
+func (p *Parser) parseEachStatement(expr tree.Expr) tree.Stmt {
+	eachStmt := &tree.EachStmt{Piece: *p.piece, Iterable: expr}
+	p.move()
+	eachStmt.Element = p.parseLoopVariable()
+	if p.piece.Kind == lexer.Comma {
+		p.move()
+		index := eachStmt.Element
+		eachStmt.Index = &index
+		eachStmt.Element = p.parseLoopVariable()
+	}
+	if p.piece.Kind != lexer.BraceOpen {
+		p.fail("Expected '{' after the names of 'ovvonrum'")
+	}
+	eachStmt.Body = p.parseLoopBody()
+	return eachStmt
+}
+
+func (p *Parser) parseLoopVariable() tree.Identifier {
+	if p.piece.Kind != lexer.Identifier {
+		p.fail("Expected a name after 'ovvonrum' got %s", p.piece.Value)
+	}
+	name := tree.Identifier{Piece: *p.piece, Name: p.piece.Value}
+	p.move()
+	return name
+}
+
+// parseLoopBody parses the block of a loop, where niruthu and thodar may
+// be used.
+func (p *Parser) parseLoopBody() *tree.Block {
+	p.loops++
+	body := p.parseBlockStatement()
+	p.loops--
+	return body
+}
+
+func (p *Parser) parseBranchStatement() tree.Stmt {
+	if p.loops == 0 {
+		p.fail("'%s' can only be used inside a loop", p.piece.Value)
+	}
+	branchStmt := &tree.BranchStmt{Piece: *p.piece}
+	p.move()
+	if p.piece.Kind != lexer.Eol {
+		p.fail("Expected ;")
+	}
+	p.move()
+	return branchStmt
+}
+
+// parseTryStatement parses muyarchi { } pidi name { } and the optional
+// iruthiyaga { } after it.
+func (p *Parser) parseTryStatement() tree.Stmt {
+	tryStmt := &tree.TryStmt{Piece: *p.piece}
+	p.move()
+	if p.piece.Kind != lexer.BraceOpen {
+		p.fail("Expected '{' after 'muyarchi'")
+	}
+	tryStmt.Body = p.parseBlockStatement()
+	if p.piece.Kind != lexer.Catch {
+		p.fail("Expected 'pidi' after the block of 'muyarchi'")
+	}
+	tryStmt.Catch = *p.piece
+	p.move()
+	if p.piece.Kind != lexer.Identifier {
+		p.fail("Expected a name after 'pidi' got %s", p.piece.Value)
+	}
+	tryStmt.Name = tree.Identifier{Piece: *p.piece, Name: p.piece.Value}
+	p.move()
+	if p.piece.Kind != lexer.BraceOpen {
+		p.fail("Expected '{' after the name of 'pidi'")
+	}
+	tryStmt.Handler = p.parseBlockStatement()
+	if p.piece.Kind == lexer.Finally {
+		p.move()
+		if p.piece.Kind != lexer.BraceOpen {
+			p.fail("Expected '{' after 'iruthiyaga'")
+		}
+		tryStmt.Finally = p.parseBlockStatement()
+	}
+	return tryStmt
+}
+
+func (p *Parser) parseRaiseStatement(expr tree.Expr) tree.Stmt {
+	raiseStmt := &tree.RaiseStmt{Piece: *p.piece, Value: expr}
+	p.move()
+	if p.piece.Kind != lexer.Eol {
+		p.fail("Expected ;")
+	}
+	p.move()
+	return raiseStmt
+}
+
 func (p *Parser) parseIfStatement(expr tree.Expr) tree.Stmt {
-	ifStmt := &tree.IfStmt{Condition: expr}
+	ifStmt := &tree.IfStmt{Piece: *p.piece, Condition: expr}
 	p.move()
 	if p.piece.Kind != lexer.BraceOpen {
-		panic("Expected '{'")
+		p.fail("Expected '{'")
 	}
 	ifStmt.Then = p.parseBlockStatement()
-	if p.piece.Kind == lexer.Else {
+	for p.piece.Kind == lexer.Else {
+		illana := *p.piece
 		p.move()
-		if p.piece.Kind != lexer.BraceOpen {
-			ifStmt.Else = p.parserExpressionStatement()
-			return ifStmt
+		if p.piece.Kind == lexer.BraceOpen {
+			ifStmt.Else = p.parseBlockStatement()
+			break
+		}
+		expr := p.parseExpression(LOWEST)
+		if p.piece.Kind != lexer.If {
+			// a single statement is the last branch, a block without
+			// braces holding it
+			ifStmt.Else = &tree.Block{Statements: []tree.Stmt{p.statementAfter(expr)}}
+			break
 		}
-		ifStmt.Else = p.parseBlockStatement()
+		ifStmt.ElseIfs = append(ifStmt.ElseIfs, p.parseElseIf(illana, expr))
 	}
 	return ifStmt
 }
 
+// parseElseIf parses the block of an illana followed by a condition and
+// endral, illana and the condition are already read.
+func (p *Parser) parseElseIf(illana lexer.Piece, condition tree.Expr) *tree.ElseIf {
+	elseIf := &tree.ElseIf{Else: illana, Condition: condition}
+	elseIf.Piece = *p.piece
+	p.move()
+	if p.piece.Kind != lexer.BraceOpen {
+		p.fail("Expected '{'")
+	}
+	elseIf.Then = p.parseBlockStatement()
+	return elseIf
+}
+
 func (p *Parser) parseBlockStatement() *tree.Block {
 	block := &tree.Block{Piece: *p.piece}
 	p.move()
 	for p.piece.Kind != lexer.BraceClose {
 		stmt := parseStatement(p)
 		if stmt != nil {
-			block.Children = append(block.Children, stmt)
+			block.Statements = append(block.Statements, stmt)
 		}
 	}
 	if p.piece.Kind != lexer.BraceClose {
-		panic("Expected '}'")
+		p.fail("Expected '}'")
 	}
+	block.Close = *p.piece
 	p.move()
 	return block
 }
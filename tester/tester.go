@@ -0,0 +1,219 @@
+// Package tester runs the test files of Niral programs. A test file is
+// named like name_test.n and fails when it stops with an error, like a
+// failed assert_equal, or when a name_test.out file next to it holds
+// something else than what the test printed.
+package tester
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iam-naveen/compiler/coverage"
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+)
+
+// Suffix ends the name of every test file.
+const Suffix = "_test.n"
+
+// Options change how the tests run.
+type Options struct {
+	// Update rewrites the .out files with what the tests print instead
+	// of comparing them.
+	Update bool
+	// Coverage, when set, counts the statements the tests run.
+	Coverage *coverage.Coverage
+	// Seed starts the random generator of every test, so a test using
+	// random_int prints the same on each run.
+	Seed int64
+	// Files is the directory the file builtins of the tests may use, they
+	// are denied when it is empty.
+	Files string
+}
+
+// Result is the outcome of one test file.
+type Result struct {
+	Path     string
+	Failures []Failure
+}
+
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Failure is a reason a test failed, Pos is not valid when the failure
+// is about the whole file.
+type Failure struct {
+	Path    string
+	Pos     lexer.Position
+	Message string
+}
+
+func (f Failure) String() string {
+	if !f.Pos.IsValid() {
+		return fmt.Sprintf("%s: %s", f.Path, f.Message)
+	}
+	return fmt.Sprintf("%s:%s: %s", f.Path, f.Pos, f.Message)
+}
+
+// Discover returns the test files in paths. Directories are searched
+// recursively, skipping the ones named testdata or starting with a dot,
+// and files are taken as they are.
+func Discover(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := entry.Name()
+			if entry.IsDir() {
+				if file != path && (name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(name, Suffix) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Golden returns the file holding the output expected from a test.
+func Golden(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".out"
+}
+
+// Run runs the test file at path.
+func Run(path string, options Options) Result {
+	result := Result{Path: path}
+	fail := func(path string, pos lexer.Position, format string, args ...any) {
+		result.Failures = append(result.Failures, Failure{Path: path, Pos: pos, Message: fmt.Sprintf(format, args...)})
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fail(path, lexer.Position{}, "%s", err)
+		return result
+	}
+	_, channel := lexer.CreateLexer(source, false)
+	program, err := parser.TryParse(channel, false)
+	if err != nil {
+		var syntax *parser.Error
+		if errors.As(err, &syntax) {
+			fail(path, syntax.Piece.Pos, "%s", syntax.Message)
+		} else {
+			fail(path, lexer.Position{}, "%s", err)
+		}
+		return result
+	}
+
+	out := &bytes.Buffer{}
+	interpreter := &evaluator.Interpreter{
+		In:    strings.NewReader(""),
+		Out:   out,
+		Path:  path,
+		Rand:  rand.New(rand.NewSource(options.Seed)),
+		Files: options.Files,
+	}
+	if options.Coverage != nil {
+		options.Coverage.Add(path, source, program)
+		interpreter.Hook = options.Coverage
+	}
+	if err := interpreter.Run(program, object.NewEnvironment()); err != nil {
+		var runtime *evaluator.RuntimeError
+		if errors.As(err, &runtime) {
+			fail(path, runtime.Pos, "%s", runtime.Message)
+		} else {
+			fail(path, lexer.Position{}, "%s", err)
+		}
+	}
+
+	golden := Golden(path)
+	if options.Update {
+		// a test printing nothing only needs a file to keep it quiet
+		if _, err := os.Stat(golden); out.Len() == 0 && errors.Is(err, fs.ErrNotExist) {
+			return result
+		}
+		if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+			fail(golden, lexer.Position{}, "%s", err)
+		}
+		return result
+	}
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		return result
+	}
+	if err != nil {
+		fail(golden, lexer.Position{}, "%s", err)
+		return result
+	}
+	if line, got, expected, differ := firstDifference(out.String(), string(want)); differ {
+		fail(golden, lexer.Position{}, "line %d: got %s, want %s", line, got, expected)
+	}
+	return result
+}
+
+// firstDifference finds the first line where the output differs from
+// the expected one, got and want are quoted or tell that the text ended.
+func firstDifference(output, expected string) (line int, got, want string, differ bool) {
+	gotLines := strings.Split(output, "\n")
+	wantLines := strings.Split(expected, "\n")
+	quote := func(lines []string, n int) string {
+		if n >= len(lines) {
+			return "end of output"
+		}
+		return strconv.Quote(lines[n])
+	}
+	for n := 0; n < max(len(gotLines), len(wantLines)); n++ {
+		got, want = quote(gotLines, n), quote(wantLines, n)
+		if got != want {
+			return n + 1, got, want, true
+		}
+	}
+	return 0, "", "", false
+}
+
+// Summary prints a line for each test file, the failures of the ones that
+// failed and the totals. It reports whether every test passed.
+func Summary(out io.Writer, results []Result) bool {
+	failed := 0
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Fprintf(out, "ok    %s\n", result.Path)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "FAIL  %s\n", result.Path)
+		for _, failure := range result.Failures {
+			fmt.Fprintf(out, "      %s\n", failure)
+		}
+	}
+	fmt.Fprintf(out, "%d passed, %d failed\n", len(results)-failed, failed)
+	return failed == 0
+}
@@ -0,0 +1,141 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// write creates the files under dir, named by their slash separated path.
+func write(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, map[string]string{
+		"math_test.n":       "yen a = 2 * 3;\nassert_equal(a, 6);\na sollu;\n",
+		"math_test.out":     "6\n",
+		"sub/str_test.n":    "sol s = \"ab\";\n\"hi\" sollu;\nassert_equal(s neelam, 3);\n",
+		"sub/str_test.out":  "bye\n",
+		"syntax_test.n":     "yen a = ;\n",
+		"main.n":            "assert_true(illai);\n",
+		"testdata/x_test.n": "assert_true(illai);\n",
+		".hidden/y_test.n":  "assert_true(illai);\n",
+		"output_test.n":     "\"a\" sollu;\n",
+		"output_test.out":   "a\nb\n",
+	})
+	tests, err := Discover([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []Result{}
+	for _, path := range tests {
+		results = append(results, Run(path, Options{}))
+	}
+	out := &bytes.Buffer{}
+	if Summary(out, results) {
+		t.Error("the summary should report failures")
+	}
+	expected := `ok    DIR/math_test.n
+FAIL  DIR/output_test.n
+      DIR/output_test.out: line 2: got "", want "b"
+FAIL  DIR/sub/str_test.n
+      DIR/sub/str_test.n:3:1: assert_equal: got 2, want 3
+      DIR/sub/str_test.out: line 1: got "hi", want "bye"
+FAIL  DIR/syntax_test.n
+      DIR/syntax_test.n:1:9: No prefix handler for ;
+1 passed, 3 failed
+`
+	if got := strings.ReplaceAll(filepath.ToSlash(out.String()), filepath.ToSlash(dir), "DIR"); got != expected {
+		t.Errorf("got\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, map[string]string{
+		"dice_test.n": "random_int(1, 1000000) sollu;\nshuffle([1, 2, 3, 4, 5, 6, 7, 8]) sollu;\n",
+	})
+	path := filepath.Join(dir, "dice_test.n")
+	if result := Run(path, Options{Update: true, Seed: 7}); !result.Passed() {
+		t.Fatal(result.Failures)
+	}
+	// the same seed prints the same numbers
+	if result := Run(path, Options{Seed: 7}); !result.Passed() {
+		t.Error(result.Failures)
+	}
+	if result := Run(path, Options{Seed: 8}); result.Passed() {
+		t.Error("another seed should print other numbers")
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, map[string]string{
+		"read_test.n": "assert_equal(read_file(\"data.txt\"), \"x\");\n",
+		"data.txt":    "x",
+	})
+	path := filepath.Join(dir, "read_test.n")
+	// the files next to a test are only read when they are given
+	if result := Run(path, Options{}); result.Passed() {
+		t.Error("the file builtins should be turned off")
+	}
+	if result := Run(path, Options{Files: dir}); !result.Passed() {
+		t.Error(result.Failures)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, map[string]string{
+		"a_test.n":     "\"new\" sollu;\n",
+		"a_test.out":   "old\n",
+		"quiet_test.n": "assert_true(aam);\n",
+	})
+	for _, name := range []string{"a_test.n", "quiet_test.n"} {
+		if result := Run(filepath.Join(dir, name), Options{Update: true}); !result.Passed() {
+			t.Errorf("%s failed: %v", name, result.Failures)
+		}
+	}
+	if golden, _ := os.ReadFile(filepath.Join(dir, "a_test.out")); string(golden) != "new\n" {
+		t.Errorf("a_test.out holds %q", golden)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "quiet_test.out")); !os.IsNotExist(err) {
+		t.Error("a test printing nothing should not get an output file")
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	tests := []struct {
+		output, expected string
+		difference       string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nc\n", "a\nb\n", `2 "c" "b"`},
+		{"a\n", "a", `2 "" end of output`},
+		{"a", "a\n", `2 end of output ""`},
+	}
+	for _, tt := range tests {
+		line, got, want, differ := firstDifference(tt.output, tt.expected)
+		difference := ""
+		if differ {
+			difference = fmt.Sprintf("%d %s %s", line, got, want)
+		}
+		if difference != tt.difference {
+			t.Errorf("%q %q: got %s, want %s", tt.output, tt.expected, difference, tt.difference)
+		}
+	}
+}
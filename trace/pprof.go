@@ -0,0 +1,142 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WritePprof writes the profile as a gzipped profile.proto, the format
+// read by go tool pprof. Each line of the source is a function named
+// after its text, so pprof can list, graph and sum the lines.
+func (p *Profiler) WritePprof(out io.Writer) error {
+	lines := p.Lines()
+	if p.started.IsZero() {
+		p.started = p.now()
+	}
+	table := []string{""}
+	index := map[string]int64{"": 0}
+	intern := func(s string) int64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = int64(len(table))
+		table = append(table, s)
+		return index[s]
+	}
+
+	profile := &message{}
+	for _, valueType := range [][2]string{{"count", "count"}, {"time", "nanoseconds"}} {
+		profile.message(1, valueTypeMessage(intern(valueType[0]), intern(valueType[1])))
+	}
+
+	ids := map[int]uint64{}
+	for i, line := range lines {
+		ids[line.Line] = uint64(i + 1)
+	}
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := p.samples[key]
+		locations := []uint64{}
+		for _, line := range s.stack {
+			locations = append(locations, ids[line])
+		}
+		sample := &message{}
+		sample.packed(1, locations)
+		sample.packed(2, []uint64{uint64(s.count), uint64(s.flat.Nanoseconds())})
+		profile.message(2, sample)
+	}
+
+	filename := intern(p.path)
+	for _, line := range lines {
+		id := ids[line.Line]
+		position := &message{}
+		position.uint(1, id)
+		position.uint(2, uint64(line.Line))
+		location := &message{}
+		location.uint(1, id)
+		location.message(4, position)
+		profile.message(4, location)
+
+		name := intern(fmt.Sprintf("%d: %s", line.Line, line.Text))
+		function := &message{}
+		function.uint(1, id)
+		function.uint(2, uint64(name))
+		function.uint(3, uint64(name))
+		function.uint(4, uint64(filename))
+		function.uint(5, uint64(line.Line))
+		profile.message(5, function)
+	}
+
+	period := valueTypeMessage(intern("time"), intern("nanoseconds"))
+	for _, s := range table {
+		profile.bytes(6, []byte(s))
+	}
+	profile.uint(9, uint64(p.started.UnixNano()))
+	profile.uint(10, uint64(p.now().Sub(p.started).Nanoseconds()))
+	profile.message(11, period)
+	profile.uint(12, 1)
+
+	zipped := gzip.NewWriter(out)
+	if _, err := zipped.Write(profile.Bytes()); err != nil {
+		return err
+	}
+	return zipped.Close()
+}
+
+func valueTypeMessage(kind, unit int64) *message {
+	valueType := &message{}
+	valueType.uint(1, uint64(kind))
+	valueType.uint(2, uint64(unit))
+	return valueType
+}
+
+// message encodes the protocol buffer fields the profile needs.
+type message struct {
+	bytes.Buffer
+}
+
+func (m *message) varint(x uint64) {
+	for x >= 0x80 {
+		m.WriteByte(byte(x) | 0x80)
+		x >>= 7
+	}
+	m.WriteByte(byte(x))
+}
+
+func (m *message) tag(field int, wireType uint64) {
+	m.varint(uint64(field)<<3 | wireType)
+}
+
+// uint writes a varint field, zero is the default and is left out.
+func (m *message) uint(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	m.tag(field, 0)
+	m.varint(x)
+}
+
+func (m *message) bytes(field int, b []byte) {
+	m.tag(field, 2)
+	m.varint(uint64(len(b)))
+	m.Write(b)
+}
+
+func (m *message) message(field int, inner *message) {
+	m.bytes(field, inner.Bytes())
+}
+
+func (m *message) packed(field int, xs []uint64) {
+	inner := &message{}
+	for _, x := range xs {
+		inner.varint(x)
+	}
+	m.bytes(field, inner.Bytes())
+}
@@ -0,0 +1,172 @@
+package trace
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Profiler counts how often each line runs and how long it takes. Flat
+// time is spent in the statements of the line itself, cumulative time
+// also covers the blocks they run.
+type Profiler struct {
+	evaluator.NopHook
+
+	path    string
+	source  []byte
+	now     func() time.Time
+	started time.Time
+	frames  []frame
+	active  map[int]int // frames open for each line
+	lines   map[int]*Line
+	samples map[string]*sample
+}
+
+// Line is what the profiler measured for one line of the source.
+type Line struct {
+	Line  int
+	Count int64
+	Flat  time.Duration
+	Cum   time.Duration
+	Text  string
+}
+
+// frame is a statement that is running.
+type frame struct {
+	line     int
+	start    time.Time
+	children time.Duration
+}
+
+// sample is the flat cost of the statements run under the same stack of
+// lines, innermost first.
+type sample struct {
+	stack []int
+	count int64
+	flat  time.Duration
+}
+
+func NewProfiler(path string, source []byte) *Profiler {
+	return &Profiler{
+		path:    path,
+		source:  source,
+		now:     time.Now,
+		active:  map[int]int{},
+		lines:   map[int]*Line{},
+		samples: map[string]*sample{},
+	}
+}
+
+func (p *Profiler) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	if p.started.IsZero() {
+		p.started = p.now()
+	}
+	line := tree.SpanOf(stmt).Start.Line
+	p.active[line]++
+	p.frames = append(p.frames, frame{line: line, start: p.now()})
+}
+
+func (p *Profiler) AfterStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	p.pop(p.now())
+}
+
+// pop ends the innermost running statement.
+func (p *Profiler) pop(end time.Time) {
+	top := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	elapsed := end.Sub(top.start)
+	if len(p.frames) > 0 {
+		p.frames[len(p.frames)-1].children += elapsed
+	}
+
+	line := p.lines[top.line]
+	if line == nil {
+		line = &Line{Line: top.line, Text: p.text(top.line)}
+		p.lines[top.line] = line
+	}
+	line.Count++
+	line.Flat += elapsed - top.children
+	// a line running inside itself is only counted once
+	p.active[top.line]--
+	if p.active[top.line] == 0 {
+		line.Cum += elapsed
+	}
+
+	stack := []int{top.line}
+	for i := len(p.frames) - 1; i >= 0; i-- {
+		stack = append(stack, p.frames[i].line)
+	}
+	key := fmt.Sprint(stack)
+	if p.samples[key] == nil {
+		p.samples[key] = &sample{stack: stack}
+	}
+	p.samples[key].count++
+	p.samples[key].flat += elapsed - top.children
+}
+
+// finish ends the statements a runtime error left running.
+func (p *Profiler) finish() {
+	end := p.now()
+	for len(p.frames) > 0 {
+		p.pop(end)
+	}
+}
+
+// Lines returns the measured lines in source order.
+func (p *Profiler) Lines() []Line {
+	p.finish()
+	lines := []Line{}
+	for _, line := range p.lines {
+		lines = append(lines, *line)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Line < lines[j].Line })
+	return lines
+}
+
+// WriteTable writes the lines as a text table.
+func (p *Profiler) WriteTable(out io.Writer) error {
+	rows := [][]string{{"line", "count", "flat", "cum", "source"}}
+	for _, line := range p.Lines() {
+		rows = append(rows, []string{
+			strconv.Itoa(line.Line),
+			strconv.FormatInt(line.Count, 10),
+			line.Flat.String(),
+			line.Cum.String(),
+			line.Text,
+		})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for _, row := range rows {
+		cells := []string{}
+		for i, cell := range row[:len(row)-1] {
+			cells = append(cells, strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))+cell)
+		}
+		cells = append(cells, row[len(row)-1])
+		if _, err := fmt.Fprintln(out, strings.Join(cells, "  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// text returns the source of the line without its indentation.
+func (p *Profiler) text(line int) string {
+	lines := strings.Split(string(p.source), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
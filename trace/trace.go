@@ -0,0 +1,136 @@
+// Package trace watches programs run by the evaluator: the tracer prints
+// each statement as it runs and the profiler counts and times them.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+// Tracer prints each statement run with its position and the variables
+// it changed. Statements holding a block are printed before their block
+// runs, the others after they ran so their changes can follow them.
+type Tracer struct {
+	evaluator.NopHook
+
+	out       io.Writer
+	path      string
+	source    []byte
+	snapshots []map[string]string // values before the running statements
+}
+
+func NewTracer(out io.Writer, path string, source []byte) *Tracer {
+	return &Tracer{out: out, path: path, source: source}
+}
+
+func (t *Tracer) BeforeStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	if body(stmt) != nil {
+		t.print(stmt, depth, "")
+	}
+	t.snapshots = append(t.snapshots, snapshot(env))
+}
+
+func (t *Tracer) AfterStatement(stmt tree.Stmt, env *object.Environment, depth int) {
+	before := t.snapshots[len(t.snapshots)-1]
+	t.snapshots = t.snapshots[:len(t.snapshots)-1]
+	if body(stmt) != nil {
+		return
+	}
+	changes := []string{}
+	after := snapshot(env)
+	for _, name := range names(env) {
+		if value, ok := before[name]; !ok || value != after[name] {
+			changes = append(changes, name+"="+after[name])
+		}
+	}
+	t.print(stmt, depth, strings.Join(changes, " "))
+}
+
+func (t *Tracer) OnError(stmt tree.Stmt, err *object.Error) {
+	if stmt == nil {
+		fmt.Fprintf(t.out, "%s\terror: %s\n", t.path, err.Message)
+		return
+	}
+	start := tree.SpanOf(stmt).Start
+	fmt.Fprintf(t.out, "%s:%d:%d\terror: %s\n", t.path, start.Line, start.Column, err.Message)
+}
+
+func (t *Tracer) print(stmt tree.Stmt, depth int, changes string) {
+	start := tree.SpanOf(stmt).Start
+	line := fmt.Sprintf("%s:%d:%d\t%s%s", t.path, start.Line, start.Column, strings.Repeat("    ", depth), Text(stmt, t.source))
+	if changes != "" {
+		line += "\t" + changes
+	}
+	fmt.Fprintln(t.out, line)
+}
+
+// Text returns the source of the statement on one line, up to its block
+// for the statements that hold one.
+func Text(stmt tree.Stmt, source []byte) string {
+	span := tree.SpanOf(stmt)
+	if !span.IsValid() {
+		return ""
+	}
+	end := span.End.Offset
+	if block := body(stmt); block != nil && block.Piece.Pos.IsValid() {
+		end = block.Piece.Pos.Offset
+	}
+	if end > len(source) || span.Start.Offset > end {
+		return ""
+	}
+	return strings.Join(strings.Fields(string(source[span.Start.Offset:end])), " ")
+}
+
+// body returns the block run by the statement, if it has one.
+func body(stmt tree.Stmt) *tree.Block {
+	switch stmt := stmt.(type) {
+	case *tree.IfStmt:
+		return stmt.Then
+	case *tree.WhileStmt:
+		return stmt.Body
+	case *tree.ForStmt:
+		return stmt.Body
+	case *tree.EachStmt:
+		return stmt.Body
+	case *tree.TryStmt:
+		return stmt.Body
+	}
+	return nil
+}
+
+// names returns the variables visible in env, inner scopes first.
+func names(env *object.Environment) []string {
+	seen := map[string]bool{}
+	visible := []string{}
+	for scope := env; scope != nil; scope = scope.Outer() {
+		for _, name := range scope.Names() {
+			if !seen[name] {
+				seen[name] = true
+				visible = append(visible, name)
+			}
+		}
+	}
+	return visible
+}
+
+func snapshot(env *object.Environment) map[string]string {
+	values := map[string]string{}
+	for _, name := range names(env) {
+		value, _ := env.Get(name)
+		values[name] = show(value)
+	}
+	return values
+}
+
+func show(value object.Object) string {
+	if value.Type() == object.STRING_OBJ {
+		return strconv.Quote(value.Inspect())
+	}
+	return value.Inspect()
+}
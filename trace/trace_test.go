@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+)
+
+const program = `yen i = 0;
+sol s = "";
+i < 2 varaikkum {
+    i = i + 1;
+    s = s + i;
+}
+s sollu;
+yen b = s;
+`
+
+func run(t *testing.T, hook evaluator.Hook) error {
+	t.Helper()
+	_, channel := lexer.CreateLexer([]byte(program), false)
+	interpreter := &evaluator.Interpreter{Out: &bytes.Buffer{}, Hook: hook}
+	return interpreter.Run(parser.Parse(channel, false), object.NewEnvironment())
+}
+
+func TestTracer(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := run(t, NewTracer(out, "main.n", []byte(program))); err == nil {
+		t.Fatal("expected the last declaration to fail")
+	}
+	expected := `main.n:1:1	yen i = 0	i=0
+main.n:2:1	sol s = ""	s=""
+main.n:3:1	i < 2 varaikkum
+main.n:4:5	    i = i + 1	i=1
+main.n:5:5	    s = s + i	s="1"
+main.n:4:5	    i = i + 1	i=2
+main.n:5:5	    s = s + i	s="12"
+main.n:7:1	s sollu
+main.n:8:1	error: Cannot Assign STRING to INTEGER variable
+`
+	if out.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", out, expected)
+	}
+}
+
+// profile runs the program with a clock that ticks a microsecond on each
+// reading.
+func profile(t *testing.T) *Profiler {
+	t.Helper()
+	profiler := NewProfiler("main.n", []byte(program))
+	clock := time.Unix(0, 0)
+	profiler.now = func() time.Time {
+		clock = clock.Add(time.Microsecond)
+		return clock
+	}
+	run(t, profiler)
+	return profiler
+}
+
+func TestProfileTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := profile(t).WriteTable(out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `line  count  flat  cum  source
+   1      1   1µs  1µs  yen i = 0;
+   2      1   1µs  1µs  sol s = "";
+   3      1   5µs  9µs  i < 2 varaikkum {
+   4      2   2µs  2µs  i = i + 1;
+   5      2   2µs  2µs  s = s + i;
+   7      1   1µs  1µs  s sollu;
+   8      1   1µs  1µs  yen b = s;
+`
+	if out.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", out, expected)
+	}
+}
+
+func TestPprof(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := profile(t).WritePprof(out); err != nil {
+		t.Fatal(err)
+	}
+	zipped, err := gzip.NewReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"count", "nanoseconds", "main.n", "3: i < 2 varaikkum {", "5: s = s + i;"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("the profile has no %q", s)
+		}
+	}
+}
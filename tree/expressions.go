@@ -2,6 +2,7 @@ package tree
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/iam-naveen/compiler/lexer"
@@ -20,6 +21,10 @@ func (i *Identifier) String() string {
 	return i.Name
 }
 
+func (i *Identifier) Children() []Node {
+	return nil
+}
+
 func (i *Identifier) Expr() {}
 
 func (s *Identifier) print(level int, prefix, out string, last bool) string {
@@ -40,6 +45,10 @@ func (n *Number) String() string {
 	return fmt.Sprintf("%d", n.Value)
 }
 
+func (n *Number) Children() []Node {
+	return nil
+}
+
 func (n *Number) Expr() {}
 
 func (s *Number) print(level int, prefix, out string, last bool) string {
@@ -47,6 +56,41 @@ func (s *Number) print(level int, prefix, out string, last bool) string {
 	return out
 }
 
+// ============================
+// ========  FLOAT  ===========
+// ============================
+
+// Float is a number written with a fraction, like 2.5.
+type Float struct {
+	Piece lexer.Piece
+	Value float64
+}
+
+func (f *Float) String() string {
+	return FormatFloat(f.Value)
+}
+
+// FormatFloat writes a float the way it is written in a program, a whole
+// number keeps its point so it reads as a float again.
+func FormatFloat(value float64) string {
+	s := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".IN") {
+		s += ".0"
+	}
+	return s
+}
+
+func (f *Float) Children() []Node {
+	return nil
+}
+
+func (f *Float) Expr() {}
+
+func (f *Float) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s %s\n", prefix, f)
+	return out
+}
+
 // ============================
 // ========  STRING  ==========
 // ============================
@@ -60,6 +104,10 @@ func (s *StringLiteral) String() string {
 	return s.Value
 }
 
+func (s *StringLiteral) Children() []Node {
+	return nil
+}
+
 func (s *StringLiteral) Expr() {}
 
 func (s *StringLiteral) print(level int, prefix, out string, last bool) string {
@@ -80,12 +128,17 @@ func (b *Boolean) String() string {
 	return fmt.Sprintf("%t", b.Value)
 }
 
+func (b *Boolean) Children() []Node {
+	return nil
+}
+
 func (b *Boolean) Expr() {}
 
 func (b *Boolean) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, b)
 	return out
 }
+
 // ===========================
 // === COMPLEX EXPRESSIONS ===
 // ===========================
@@ -94,17 +147,44 @@ func (b *Boolean) print(level int, prefix, out string, last bool) string {
 // ======== ARRAY ==============
 // ============================
 
+// Array is a list of values, [a, b, ...].
 type Array struct {
 	Piece    lexer.Piece
 	Elements []Expr
+	Close    lexer.Piece
 }
 
 func (a *Array) String() string {
-	return fmt.Sprintf("%v", a.Elements)
+	elements := []string{}
+	for _, element := range a.Elements {
+		elements = append(elements, element.String())
+	}
+	return fmt.Sprintf("[%s]", strings.Join(elements, ", "))
+}
+
+func (a *Array) Children() []Node {
+	nodes := []Node{}
+	for _, e := range a.Elements {
+		nodes = append(nodes, e)
+	}
+	return nodes
 }
 
 func (a *Array) Expr() {}
 
+func (a *Array) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s []\n", prefix)
+	margin := strings.Repeat(pipe+indent, level+1)
+	for i, element := range a.Elements {
+		branch := Tee
+		if i == len(a.Elements)-1 {
+			branch = Last
+		}
+		out += printNode(element, level+1, branch, margin, i == len(a.Elements)-1)
+	}
+	return out
+}
+
 // ==============================
 // ======== Access ==============
 // ==============================
@@ -113,12 +193,17 @@ type Access struct {
 	Piece lexer.Piece
 	Left  Expr
 	Index Expr
+	Close lexer.Piece
 }
 
 func (a *Access) String() string {
 	return fmt.Sprintf("%v[%v]", a.Left, a.Index)
 }
 
+func (a *Access) Children() []Node {
+	return []Node{a.Left, a.Index}
+}
+
 func (a *Access) Expr() {}
 
 func (a *Access) print(level int, prefix, out string, last bool) string {
@@ -126,7 +211,6 @@ func (a *Access) print(level int, prefix, out string, last bool) string {
 	return out
 }
 
-
 // ============================
 // ======== BINARY ============
 // ============================
@@ -141,13 +225,17 @@ func (b *Binary) String() string {
 	return fmt.Sprintf("(%v %s %v)", b.Left, b.Operator.Value, b.Right)
 }
 
+func (b *Binary) Children() []Node {
+	return []Node{b.Left, b.Right}
+}
+
 func (b *Binary) Expr() {}
 
 func (s *Binary) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, s.Operator.Value)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Left.print(level+1, Tee, margin, last)
-	out += s.Right.print(level+1, Last, margin, false)
+	out += printNode(s.Left, level+1, Tee, margin, last)
+	out += printNode(s.Right, level+1, Last, margin, false)
 	return out
 }
 
@@ -164,12 +252,16 @@ func (a *Assign) String() string {
 	return fmt.Sprintf("%v = %v", a.Left, a.Right)
 }
 
+func (a *Assign) Children() []Node {
+	return []Node{&a.Left, a.Right}
+}
+
 func (a *Assign) Expr() {}
 
 func (a *Assign) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, a.Left.Name)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += a.Right.print(level+1, Last, margin, true)
+	out += printNode(a.Right, level+1, Last, margin, true)
 	return out
 }
 
@@ -186,12 +278,16 @@ func (p *Prefix) String() string {
 	return fmt.Sprintf("(%s%v)", p.Operator.Value, p.Right)
 }
 
+func (p *Prefix) Children() []Node {
+	return []Node{p.Right}
+}
+
 func (p *Prefix) Expr() {}
 
 func (s *Prefix) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, s.Operator.Value)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Right.print(level+1, Last, margin, true)
+	out += printNode(s.Right, level+1, Last, margin, true)
 	return out
 }
 
@@ -208,12 +304,16 @@ func (p *Print) String() string {
 	return fmt.Sprintf("print %v", p.Value)
 }
 
+func (p *Print) Children() []Node {
+	return []Node{p.Value}
+}
+
 func (p *Print) Expr() {}
 
 func (p *Print) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, "print")
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += p.Value.print(level+1, Last, margin, true)
+	out += printNode(p.Value, level+1, Last, margin, true)
 	return out
 }
 
@@ -221,25 +321,38 @@ func (p *Print) print(level int, prefix, out string, last bool) string {
 // ============= INPUT =================
 // =====================================
 
+// Input reads a value into a new variable, after writing Prompt when it
+// is not nil.
 type Input struct {
 	Piece    lexer.Piece
 	Variable Identifier
 	DataType string
+	Prompt   Expr
 }
 
-// Stmt implements Stmt.
-func (*Input) Stmt() {
-	panic("unimplemented")
-}
+func (*Input) Stmt() {}
 
 func (i *Input) String() string {
+	if i.Prompt != nil {
+		return fmt.Sprintf("get %v asking %v", i.Variable, i.Prompt)
+	}
 	return fmt.Sprintf("get %v", i.Variable)
 }
 
+func (i *Input) Children() []Node {
+	if i.Prompt != nil {
+		return []Node{&i.Variable, i.Prompt}
+	}
+	return []Node{&i.Variable}
+}
+
 func (i *Input) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, "input")
 	margin := strings.Repeat(pipe+indent, level+1)
 	out += fmt.Sprintf("%s %s\n", margin, i.Variable)
+	if i.Prompt != nil {
+		out += printNode(i.Prompt, level+1, Last, margin, true)
+	}
 	return out
 }
 
@@ -256,12 +369,58 @@ func (l *Length) String() string {
 	return fmt.Sprintf("length %v", l.Value)
 }
 
+func (l *Length) Children() []Node {
+	return []Node{l.Value}
+}
+
 func (l *Length) Expr() {}
 
 func (l *Length) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, "length")
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += l.Value.print(level+1, Last, margin, true)
+	out += printNode(l.Value, level+1, Last, margin, true)
+	return out
+}
+
+// =====================================
+// ============= CALL ==================
+// =====================================
+
+// Call calls a builtin function, name(arg, ...).
+type Call struct {
+	Function Identifier
+	Args     []Expr
+	Close    lexer.Piece
+}
+
+func (c *Call) String() string {
+	args := []string{}
+	for _, arg := range c.Args {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", c.Function.Name, strings.Join(args, ", "))
+}
+
+func (c *Call) Children() []Node {
+	nodes := []Node{&c.Function}
+	for _, arg := range c.Args {
+		nodes = append(nodes, arg)
+	}
+	return nodes
+}
+
+func (c *Call) Expr() {}
+
+func (c *Call) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s call %s\n", prefix, c.Function.Name)
+	margin := strings.Repeat(pipe+indent, level+1)
+	for i, arg := range c.Args {
+		branch := Tee
+		if i == len(c.Args)-1 {
+			branch = Last
+		}
+		out += printNode(arg, level+1, branch, margin, i == len(c.Args)-1)
+	}
 	return out
 }
 
@@ -280,12 +439,20 @@ func (i *If) String() string {
 	return fmt.Sprintf("if %v %v", i.Condition, i.Body)
 }
 
+func (i *If) Children() []Node {
+	nodes := []Node{i.Condition, i.Body}
+	if i.Alternate != nil {
+		nodes = append(nodes, i.Alternate)
+	}
+	return nodes
+}
+
 func (i *If) Expr() {}
 
 func (i *If) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, "if")
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += i.Condition.print(level+1, Tee, margin, false)
+	out += printNode(i.Condition, level+1, Tee, margin, false)
 	if i.Alternate != nil {
 		out += i.Body.print(level+1, Tee, margin, false)
 		out += i.Alternate.print(level+1, Last, margin, true)
@@ -308,6 +475,10 @@ func (e *Else) String() string {
 	return fmt.Sprintf("else %v", e.Body)
 }
 
+func (e *Else) Children() []Node {
+	return []Node{&e.Body}
+}
+
 func (e *Else) Expr() {}
 
 func (e *Else) print(level int, prefix, out string, last bool) string {
@@ -0,0 +1,1017 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/iam-naveen/compiler/lexer"
+)
+
+// The JSON form of the tree is meant for tools outside of Go. Every node
+// is an object whose first two keys are
+//
+//	"kind"  the node type, one of the type names of this package,
+//	        like "Declaration" or "Binary"
+//	"span"  {"start": position, "end": position}, omitted when unknown
+//
+// where a position is {"offset": bytes, "line": n, "column": runes},
+// starting from 0, 1 and 1. The remaining keys depend on the kind:
+//
+//	Program         statements: [node], comments: [Comment]
+//	Comment         text: string, including the leading //
+//	Block           statements: [node]
+//	ExpressionStmt  expression: node
+//	Declaration     datatype: "INTEGER" | "STRING" | "ARRAY" | "FLOAT" |
+//	                "HASH", name: string,
+//	                nameSpan: span, value: node | null
+//	Input           datatype: "INTEGER" | "STRING" | "ARRAY" | "FLOAT", variable: Identifier,
+//	                prompt: node | null
+//	IfStmt          condition: node, then: Block, elseIfs: [ElseIf],
+//	                else: Block | null, an IfStmt for else is read as
+//	                the first of the else ifs
+//	ElseIf          condition: node, then: Block
+//	WhileStmt       condition: node, body: Block
+//	ForStmt         count: node, body: Block
+//	EachStmt        iterable: node, index: Identifier | null,
+//	                element: Identifier, body: Block
+//	PrintStmt       values: [node], newline: boolean, true when missing
+//	ImportStmt      path: StringLiteral
+//	BranchStmt      break: boolean, false for a continue
+//	TryStmt         body: Block, name: Identifier, handler: Block,
+//	                finally: Block | null
+//	RaiseStmt       value: node
+//	Function        name: string, args: [node], return: string, body: Block
+//	ReturnStmt      value: node | null
+//	Identifier      name: string
+//	Number          value: integer
+//	Float           value: number
+//	StringLiteral   value: string
+//	Boolean         value: boolean
+//	Array           elements: [node]
+//	Access          left: node, index: node
+//	Binary          operator: string, left: node, right: node
+//	Assign          left: Identifier, right: node
+//	Prefix          operator: string, right: node
+//	Print           value: node
+//	Length          value: node
+//	Call            function: Identifier, args: [node]
+//	If              condition: node, body: Block, alternate: Block | null
+//	Else            body: Block
+//
+// Apart from span and nameSpan, keys are always present, so an empty
+// block has "statements": [] and a missing else branch is null.
+type header struct {
+	Kind string `json:"kind"`
+	Span *Span  `json:"span,omitempty"`
+}
+
+type (
+	jsonProgram struct {
+		header
+		Statements []json.RawMessage `json:"statements"`
+		Comments   []json.RawMessage `json:"comments"`
+	}
+	jsonComment struct {
+		header
+		Text string `json:"text"`
+	}
+	jsonStatements struct {
+		header
+		Statements []json.RawMessage `json:"statements"`
+	}
+	jsonExpressionStmt struct {
+		header
+		Expression json.RawMessage `json:"expression"`
+	}
+	jsonDeclaration struct {
+		header
+		Datatype string          `json:"datatype"`
+		Name     string          `json:"name"`
+		NameSpan *Span           `json:"nameSpan,omitempty"`
+		Value    json.RawMessage `json:"value"`
+	}
+	jsonInput struct {
+		header
+		Datatype string          `json:"datatype"`
+		Variable json.RawMessage `json:"variable"`
+		Prompt   json.RawMessage `json:"prompt"`
+	}
+	jsonIfStmt struct {
+		header
+		Condition json.RawMessage   `json:"condition"`
+		Then      json.RawMessage   `json:"then"`
+		ElseIfs   []json.RawMessage `json:"elseIfs"`
+		Else      json.RawMessage   `json:"else"`
+	}
+	jsonElseIf struct {
+		header
+		Condition json.RawMessage `json:"condition"`
+		Then      json.RawMessage `json:"then"`
+	}
+	jsonLoop struct {
+		header
+		Condition json.RawMessage `json:"condition,omitempty"`
+		Count     json.RawMessage `json:"count,omitempty"`
+		Body      json.RawMessage `json:"body"`
+	}
+	jsonImportStmt struct {
+		header
+		Path json.RawMessage `json:"path"`
+	}
+	jsonFunction struct {
+		header
+		Name   string            `json:"name"`
+		Args   []json.RawMessage `json:"args"`
+		Return string            `json:"return"`
+		Body   json.RawMessage   `json:"body"`
+	}
+	jsonValue struct {
+		header
+		Value json.RawMessage `json:"value"`
+	}
+	jsonEachStmt struct {
+		header
+		Iterable json.RawMessage `json:"iterable"`
+		Index    json.RawMessage `json:"index"`
+		Element  json.RawMessage `json:"element"`
+		Body     json.RawMessage `json:"body"`
+	}
+	jsonTryStmt struct {
+		header
+		Body    json.RawMessage `json:"body"`
+		Name    json.RawMessage `json:"name"`
+		Handler json.RawMessage `json:"handler"`
+		Finally json.RawMessage `json:"finally"`
+	}
+	jsonBranchStmt struct {
+		header
+		Break bool `json:"break"`
+	}
+	jsonPrintStmt struct {
+		header
+		Values  []json.RawMessage `json:"values"`
+		Newline *bool             `json:"newline"`
+	}
+	jsonIdentifier struct {
+		header
+		Name string `json:"name"`
+	}
+	jsonArray struct {
+		header
+		Elements []json.RawMessage `json:"elements"`
+	}
+	jsonAccess struct {
+		header
+		Left  json.RawMessage `json:"left"`
+		Index json.RawMessage `json:"index"`
+	}
+	jsonCall struct {
+		header
+		Function json.RawMessage   `json:"function"`
+		Args     []json.RawMessage `json:"args"`
+	}
+	jsonOperator struct {
+		header
+		Operator string          `json:"operator,omitempty"`
+		Left     json.RawMessage `json:"left,omitempty"`
+		Right    json.RawMessage `json:"right"`
+	}
+	jsonIf struct {
+		header
+		Condition json.RawMessage `json:"condition,omitempty"`
+		Body      json.RawMessage `json:"body"`
+		Alternate json.RawMessage `json:"alternate,omitempty"`
+	}
+)
+
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return MarshalNode(p)
+}
+
+func (p *Program) UnmarshalJSON(data []byte) error {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return err
+	}
+	program, ok := node.(*Program)
+	if !ok {
+		return fmt.Errorf("expected a Program, got %T", node)
+	}
+	*p = *program
+	return nil
+}
+
+// MarshalNode encodes any node of the tree.
+func MarshalNode(node Node) ([]byte, error) {
+	if node == nil {
+		return []byte("null"), nil
+	}
+	h := header{Kind: kindName(node)}
+	if span := SpanOf(node); span.IsValid() {
+		h.Span = &span
+	}
+	var v any
+	var err error
+	switch n := node.(type) {
+	case *Program:
+		s := jsonProgram{header: h, Comments: []json.RawMessage{}}
+		s.Statements, err = marshalStmts(n.Statements)
+		for _, comment := range n.Comments {
+			if err != nil {
+				break
+			}
+			var data []byte
+			data, err = MarshalNode(comment)
+			s.Comments = append(s.Comments, data)
+		}
+		v = s
+	case *Comment:
+		v = jsonComment{header: h, Text: n.Text}
+	case *Block:
+		s := jsonStatements{header: h}
+		s.Statements, err = marshalStmts(n.Statements)
+		v = s
+	case *ExpressionStmt:
+		s := jsonExpressionStmt{header: h}
+		s.Expression, err = MarshalNode(n.Expression)
+		v = s
+	case *Declaration:
+		s := jsonDeclaration{header: h, Datatype: n.Datatype, Name: n.Name.Value}
+		if n.Name.Pos.IsValid() {
+			s.NameSpan = &Span{n.Name.Pos, n.Name.End}
+		}
+		s.Value, err = marshalExpr(n.Value)
+		v = s
+	case *Input:
+		s := jsonInput{header: h, Datatype: n.DataType}
+		s.Variable, err = MarshalNode(&n.Variable)
+		if err == nil {
+			s.Prompt, err = marshalExpr(n.Prompt)
+		}
+		v = s
+	case *IfStmt:
+		s := jsonIfStmt{header: h, ElseIfs: []json.RawMessage{}, Else: json.RawMessage("null")}
+		err = marshalAll(field{&s.Condition, n.Condition}, field{&s.Then, n.Then})
+		for _, elseIf := range n.ElseIfs {
+			if err != nil {
+				break
+			}
+			var data []byte
+			data, err = MarshalNode(elseIf)
+			s.ElseIfs = append(s.ElseIfs, data)
+		}
+		if err == nil && n.Else != nil {
+			s.Else, err = MarshalNode(n.Else)
+		}
+		v = s
+	case *ElseIf:
+		s := jsonElseIf{header: h}
+		err = marshalAll(field{&s.Condition, n.Condition}, field{&s.Then, n.Then})
+		v = s
+	case *WhileStmt:
+		s := jsonLoop{header: h}
+		err = marshalAll(field{&s.Condition, n.Condition}, field{&s.Body, n.Body})
+		v = s
+	case *ForStmt:
+		s := jsonLoop{header: h}
+		err = marshalAll(field{&s.Count, n.Count}, field{&s.Body, n.Body})
+		v = s
+	case *EachStmt:
+		s := jsonEachStmt{header: h, Index: json.RawMessage("null")}
+		err = marshalAll(field{&s.Iterable, n.Iterable}, field{&s.Element, &n.Element}, field{&s.Body, n.Body})
+		if err == nil && n.Index != nil {
+			s.Index, err = MarshalNode(n.Index)
+		}
+		v = s
+	case *PrintStmt:
+		newline := n.Newline()
+		s := jsonPrintStmt{header: h, Newline: &newline}
+		s.Values, err = marshalExprs(n.Values)
+		v = s
+	case *BranchStmt:
+		v = jsonBranchStmt{header: h, Break: n.Break()}
+	case *TryStmt:
+		s := jsonTryStmt{header: h, Finally: json.RawMessage("null")}
+		err = marshalAll(field{&s.Body, n.Body}, field{&s.Name, &n.Name}, field{&s.Handler, n.Handler})
+		if err == nil && n.Finally != nil {
+			s.Finally, err = MarshalNode(n.Finally)
+		}
+		v = s
+	case *RaiseStmt:
+		s := jsonValue{header: h}
+		s.Value, err = MarshalNode(n.Value)
+		v = s
+	case *ImportStmt:
+		s := jsonImportStmt{header: h}
+		s.Path, err = MarshalNode(&n.Path)
+		v = s
+	case *Function:
+		s := jsonFunction{header: h, Name: n.Name.Value, Return: n.Return.Value}
+		if s.Args, err = marshalExprs(n.Args); err == nil {
+			s.Body, err = MarshalNode(n.Body)
+		}
+		v = s
+	case *ReturnStmt:
+		s := jsonValue{header: h}
+		s.Value, err = marshalExpr(n.Value)
+		v = s
+	case *Identifier:
+		v = jsonIdentifier{header: h, Name: n.Name}
+	case *Number:
+		v = jsonValue{header: h, Value: json.RawMessage(strconv.FormatInt(n.Value, 10))}
+	case *Float:
+		v = jsonValue{header: h, Value: json.RawMessage(n.String())}
+	case *StringLiteral:
+		s := jsonValue{header: h}
+		s.Value, err = json.Marshal(n.Value)
+		v = s
+	case *Boolean:
+		v = jsonValue{header: h, Value: json.RawMessage(strconv.FormatBool(n.Value))}
+	case *Array:
+		s := jsonArray{header: h}
+		s.Elements, err = marshalExprs(n.Elements)
+		v = s
+	case *Access:
+		s := jsonAccess{header: h}
+		err = marshalAll(field{&s.Left, n.Left}, field{&s.Index, n.Index})
+		v = s
+	case *Binary:
+		s := jsonOperator{header: h, Operator: n.Operator.Value}
+		err = marshalAll(field{&s.Left, n.Left}, field{&s.Right, n.Right})
+		v = s
+	case *Assign:
+		s := jsonOperator{header: h}
+		err = marshalAll(field{&s.Left, &n.Left}, field{&s.Right, n.Right})
+		v = s
+	case *Prefix:
+		s := jsonOperator{header: h, Operator: n.Operator.Value}
+		s.Right, err = MarshalNode(n.Right)
+		v = s
+	case *Print:
+		s := jsonValue{header: h}
+		s.Value, err = MarshalNode(n.Value)
+		v = s
+	case *Length:
+		s := jsonValue{header: h}
+		s.Value, err = MarshalNode(n.Value)
+		v = s
+	case *Call:
+		s := jsonCall{header: h}
+		if s.Function, err = MarshalNode(&n.Function); err == nil {
+			s.Args, err = marshalExprs(n.Args)
+		}
+		v = s
+	case *If:
+		s := jsonIf{header: h}
+		err = marshalAll(field{&s.Condition, n.Condition}, field{&s.Body, n.Body})
+		s.Alternate = json.RawMessage("null")
+		if err == nil && n.Alternate != nil {
+			s.Alternate, err = MarshalNode(n.Alternate)
+		}
+		v = s
+	case *Else:
+		s := jsonIf{header: h}
+		s.Body, err = MarshalNode(&n.Body)
+		v = s
+	default:
+		return nil, fmt.Errorf("cannot marshal node %T", node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+type field struct {
+	out  *json.RawMessage
+	node Node
+}
+
+func marshalAll(fields ...field) error {
+	for _, f := range fields {
+		data, err := MarshalNode(f.node)
+		if err != nil {
+			return err
+		}
+		*f.out = data
+	}
+	return nil
+}
+
+// marshalExpr encodes an optional expression, a nil Expr becomes null.
+func marshalExpr(expr Expr) (json.RawMessage, error) {
+	if expr == nil {
+		return json.RawMessage("null"), nil
+	}
+	return MarshalNode(expr)
+}
+
+func marshalStmts(stmts []Stmt) ([]json.RawMessage, error) {
+	out := []json.RawMessage{}
+	for _, stmt := range stmts {
+		data, err := MarshalNode(stmt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
+
+func marshalExprs(exprs []Expr) ([]json.RawMessage, error) {
+	out := []json.RawMessage{}
+	for _, expr := range exprs {
+		data, err := MarshalNode(expr)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
+
+func kindName(node Node) string {
+	return fmt.Sprintf("%T", node)[len("*tree."):]
+}
+
+// UnmarshalNode decodes a node encoded by MarshalNode. Positions are
+// restored for the pieces at the edges of each span, pieces in between,
+// like the operator of a Binary, are left without a position.
+func UnmarshalNode(data []byte) (Node, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("missing node")
+	}
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	span := Span{}
+	if h.Span != nil {
+		span = *h.Span
+	}
+	switch h.Kind {
+	case "Program":
+		var s jsonProgram
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		stmts, err := unmarshalStmts(s.Statements)
+		if err != nil {
+			return nil, err
+		}
+		program := &Program{Statements: stmts}
+		for _, d := range s.Comments {
+			node, err := UnmarshalNode(d)
+			if err != nil {
+				return nil, err
+			}
+			comment, ok := node.(*Comment)
+			if !ok {
+				return nil, fmt.Errorf("expected a Comment, got %s", kindName(node))
+			}
+			program.Comments = append(program.Comments, comment)
+		}
+		return program, nil
+	case "Comment":
+		var s jsonComment
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return &Comment{Piece: whole(lexer.Comment, s.Text, span), Text: s.Text}, nil
+	case "Block":
+		var s jsonStatements
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		stmts, err := unmarshalStmts(s.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &Block{
+			Piece:      atStart(lexer.BraceOpen, "{", span),
+			Statements: stmts,
+			Close:      atEnd(lexer.BraceClose, "}", span),
+		}, nil
+	case "ExpressionStmt":
+		var s jsonExpressionStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		expr, err := unmarshalExpr(s.Expression)
+		return &ExpressionStmt{Expression: expr}, err
+	case "Declaration":
+		var s jsonDeclaration
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		decl := &Declaration{
+			Piece:    atStart(lexer.DataType, keywordOf(s.Datatype), span),
+			Datatype: s.Datatype,
+			Name:     lexer.Piece{Kind: lexer.Identifier, Value: s.Name},
+		}
+		if s.NameSpan != nil {
+			decl.Name = whole(lexer.Identifier, s.Name, *s.NameSpan)
+		}
+		var err error
+		decl.Value, err = unmarshalOptionalExpr(s.Value)
+		return decl, err
+	case "Input":
+		var s jsonInput
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		variable, err := unmarshalIdentifier(s.Variable)
+		if err != nil {
+			return nil, err
+		}
+		prompt, err := unmarshalOptionalExpr(s.Prompt)
+		return &Input{
+			Piece:    atEnd(lexer.Input, "kodu", span),
+			Variable: *variable,
+			DataType: s.Datatype,
+			Prompt:   prompt,
+		}, err
+	case "IfStmt":
+		var s jsonIfStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		stmt := &IfStmt{Piece: lexer.Piece{Kind: lexer.If, Value: "endral"}}
+		var err error
+		if stmt.Condition, err = unmarshalExpr(s.Condition); err != nil {
+			return nil, err
+		}
+		if stmt.Then, err = unmarshalBlock(s.Then); err != nil {
+			return nil, err
+		}
+		for _, d := range s.ElseIfs {
+			node, err := UnmarshalNode(d)
+			if err != nil {
+				return nil, err
+			}
+			elseIf, ok := node.(*ElseIf)
+			if !ok {
+				return nil, fmt.Errorf("expected an ElseIf, got %s", kindName(node))
+			}
+			stmt.ElseIfs = append(stmt.ElseIfs, elseIf)
+		}
+		if string(s.Else) == "null" || len(s.Else) == 0 {
+			return stmt, nil
+		}
+		node, err := UnmarshalNode(s.Else)
+		if err != nil {
+			return nil, err
+		}
+		switch alternate := node.(type) {
+		case *Block:
+			stmt.Else = alternate
+		case *IfStmt:
+			stmt.ElseIfs = append(stmt.ElseIfs, &ElseIf{
+				Else:      lexer.Piece{Kind: lexer.Else, Value: "illana"},
+				Piece:     alternate.Piece,
+				Condition: alternate.Condition,
+				Then:      alternate.Then,
+			})
+			stmt.ElseIfs = append(stmt.ElseIfs, alternate.ElseIfs...)
+			stmt.Else = alternate.Else
+		default:
+			return nil, fmt.Errorf("expected a Block or an IfStmt, got %s", kindName(node))
+		}
+		return stmt, nil
+	case "ElseIf":
+		var s jsonElseIf
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		elseIf := &ElseIf{
+			Else:  atStart(lexer.Else, "illana", span),
+			Piece: lexer.Piece{Kind: lexer.If, Value: "endral"},
+		}
+		var err error
+		if elseIf.Condition, err = unmarshalExpr(s.Condition); err != nil {
+			return nil, err
+		}
+		elseIf.Then, err = unmarshalBlock(s.Then)
+		return elseIf, err
+	case "WhileStmt", "ForStmt":
+		var s jsonLoop
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		body, err := unmarshalBlock(s.Body)
+		if err != nil {
+			return nil, err
+		}
+		if h.Kind == "WhileStmt" {
+			condition, err := unmarshalExpr(s.Condition)
+			return &WhileStmt{
+				Piece:     lexer.Piece{Kind: lexer.While, Value: "varaikkum"},
+				Condition: condition,
+				Body:      body,
+			}, err
+		}
+		count, err := unmarshalExpr(s.Count)
+		return &ForStmt{
+			Piece: lexer.Piece{Kind: lexer.For, Value: "murai"},
+			Count: count,
+			Body:  body,
+		}, err
+	case "PrintStmt":
+		var s jsonPrintStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		values, err := unmarshalExprs(s.Values)
+		keyword := "sollu"
+		if s.Newline != nil && !*s.Newline {
+			keyword = "solli"
+		}
+		return &PrintStmt{Piece: atEnd(lexer.Print, keyword, span), Values: values}, err
+	case "EachStmt":
+		var s jsonEachStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		stmt := &EachStmt{Piece: lexer.Piece{Kind: lexer.Each, Value: "ovvonrum"}}
+		element, err := unmarshalIdentifier(s.Element)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Element = *element
+		if len(s.Index) > 0 && string(s.Index) != "null" {
+			if stmt.Index, err = unmarshalIdentifier(s.Index); err != nil {
+				return nil, err
+			}
+		}
+		if stmt.Body, err = unmarshalBlock(s.Body); err != nil {
+			return nil, err
+		}
+		stmt.Iterable, err = unmarshalExpr(s.Iterable)
+		return stmt, err
+	case "TryStmt":
+		var s jsonTryStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		stmt := &TryStmt{
+			Piece: atStart(lexer.Try, "muyarchi", span),
+			Catch: lexer.Piece{Kind: lexer.Catch, Value: "pidi"},
+		}
+		name, err := unmarshalIdentifier(s.Name)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Name = *name
+		if stmt.Body, err = unmarshalBlock(s.Body); err != nil {
+			return nil, err
+		}
+		if stmt.Handler, err = unmarshalBlock(s.Handler); err != nil {
+			return nil, err
+		}
+		if len(s.Finally) > 0 && string(s.Finally) != "null" {
+			stmt.Finally, err = unmarshalBlock(s.Finally)
+		}
+		return stmt, err
+	case "RaiseStmt":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		value, err := unmarshalExpr(s.Value)
+		return &RaiseStmt{Piece: atEnd(lexer.Raise, "eri", span), Value: value}, err
+	case "BranchStmt":
+		var s jsonBranchStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		// the keyword is the whole span, in whichever spelling
+		piece := lexer.Piece{Kind: lexer.Continue, Value: "thodar", Pos: span.Start, End: span.End}
+		if s.Break {
+			piece.Kind, piece.Value = lexer.Break, "niruthu"
+		}
+		return &BranchStmt{Piece: piece}, nil
+	case "ImportStmt":
+		var s jsonImportStmt
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		node, err := UnmarshalNode(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		path, ok := node.(*StringLiteral)
+		if !ok {
+			return nil, fmt.Errorf("expected a StringLiteral, got %s", kindName(node))
+		}
+		return &ImportStmt{Piece: atEnd(lexer.Import, "irakkumathi", span), Path: *path}, nil
+	case "Function":
+		var s jsonFunction
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		args, err := unmarshalExprs(s.Args)
+		if err != nil {
+			return nil, err
+		}
+		body, err := unmarshalBlock(s.Body)
+		return &Function{
+			Name:   lexer.Piece{Kind: lexer.Identifier, Value: s.Name},
+			Args:   args,
+			Return: lexer.Piece{Kind: lexer.DataType, Value: s.Return},
+			Body:   body,
+		}, err
+	case "ReturnStmt":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		value, err := unmarshalOptionalExpr(s.Value)
+		return &ReturnStmt{Value: value}, err
+	case "Identifier":
+		var s jsonIdentifier
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return &Identifier{Piece: whole(lexer.Identifier, s.Name, span), Name: s.Name}, nil
+	case "Number":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var value int64
+		if err := json.Unmarshal(s.Value, &value); err != nil {
+			return nil, err
+		}
+		return &Number{Piece: whole(lexer.Number, string(s.Value), span), Value: value}, nil
+	case "Float":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var value float64
+		if err := json.Unmarshal(s.Value, &value); err != nil {
+			return nil, err
+		}
+		return &Float{Piece: whole(lexer.Number, string(s.Value), span), Value: value}, nil
+	case "StringLiteral":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var value string
+		if err := json.Unmarshal(s.Value, &value); err != nil {
+			return nil, err
+		}
+		return &StringLiteral{Piece: whole(lexer.StringLiteral, value, span), Value: value}, nil
+	case "Boolean":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var value bool
+		if err := json.Unmarshal(s.Value, &value); err != nil {
+			return nil, err
+		}
+		word := "illai"
+		if value {
+			word = "aam"
+		}
+		return &Boolean{Piece: whole(lexer.Boolean, word, span), Value: value}, nil
+	case "Array":
+		var s jsonArray
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		elements, err := unmarshalExprs(s.Elements)
+		return &Array{
+			Piece:    atStart(lexer.BracketOpen, "[", span),
+			Elements: elements,
+			Close:    atEnd(lexer.BracketClose, "]", span),
+		}, err
+	case "Access":
+		var s jsonAccess
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		access := &Access{
+			Piece: lexer.Piece{Kind: lexer.BracketOpen, Value: "["},
+			Close: atEnd(lexer.BracketClose, "]", span),
+		}
+		var err error
+		if access.Left, err = unmarshalExpr(s.Left); err != nil {
+			return nil, err
+		}
+		access.Index, err = unmarshalExpr(s.Index)
+		return access, err
+	case "Binary", "Assign", "Prefix":
+		var s jsonOperator
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if h.Kind == "Assign" {
+			left, err := unmarshalIdentifier(s.Left)
+			if err != nil {
+				return nil, err
+			}
+			right, err := unmarshalExpr(s.Right)
+			return &Assign{Left: *left, Right: right}, err
+		}
+		operator, err := operatorPiece(s.Operator)
+		if err != nil {
+			return nil, err
+		}
+		right, err := unmarshalExpr(s.Right)
+		if err != nil {
+			return nil, err
+		}
+		if h.Kind == "Prefix" {
+			operator = atStart(operator.Kind, operator.Value, span)
+			return &Prefix{Operator: operator, Right: right}, nil
+		}
+		left, err := unmarshalExpr(s.Left)
+		return &Binary{Left: left, Operator: operator, Right: right}, err
+	case "Print", "Length":
+		var s jsonValue
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		value, err := unmarshalExpr(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		if h.Kind == "Print" {
+			return &Print{Piece: atEnd(lexer.Print, "sollu", span), Value: value}, nil
+		}
+		return &Length{Piece: atEnd(lexer.Length, "neelam", span), Value: value}, nil
+	case "Call":
+		var s jsonCall
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		function, err := unmarshalIdentifier(s.Function)
+		if err != nil {
+			return nil, err
+		}
+		args, err := unmarshalExprs(s.Args)
+		return &Call{Function: *function, Args: args, Close: atEnd(lexer.ParanClose, ")", span)}, err
+	case "If", "Else":
+		var s jsonIf
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		body, err := unmarshalBlock(s.Body)
+		if err != nil {
+			return nil, err
+		}
+		if h.Kind == "Else" {
+			return &Else{Piece: lexer.Piece{Kind: lexer.Else, Value: "illana"}, Body: *body}, nil
+		}
+		stmt := &If{Piece: lexer.Piece{Kind: lexer.If, Value: "endral"}, Body: body}
+		if stmt.Condition, err = unmarshalExpr(s.Condition); err != nil {
+			return nil, err
+		}
+		if string(s.Alternate) != "null" && len(s.Alternate) > 0 {
+			stmt.Alternate, err = unmarshalBlock(s.Alternate)
+		}
+		return stmt, err
+	}
+	return nil, fmt.Errorf("unknown node kind %q", h.Kind)
+}
+
+func unmarshalStmts(data []json.RawMessage) ([]Stmt, error) {
+	stmts := []Stmt{}
+	for _, d := range data {
+		node, err := UnmarshalNode(d)
+		if err != nil {
+			return nil, err
+		}
+		stmt, err := asStmt(node)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+func unmarshalExprs(data []json.RawMessage) ([]Expr, error) {
+	exprs := []Expr{}
+	for _, d := range data {
+		expr, err := unmarshalExpr(d)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func unmarshalExpr(data json.RawMessage) (Expr, error) {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return nil, err
+	}
+	expr, ok := node.(Expr)
+	if !ok {
+		return nil, fmt.Errorf("expected an expression, got %s", kindName(node))
+	}
+	return expr, nil
+}
+
+func unmarshalOptionalExpr(data json.RawMessage) (Expr, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	return unmarshalExpr(data)
+}
+
+func unmarshalBlock(data json.RawMessage) (*Block, error) {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := node.(*Block)
+	if !ok {
+		return nil, fmt.Errorf("expected a Block, got %s", kindName(node))
+	}
+	return block, nil
+}
+
+func unmarshalIdentifier(data json.RawMessage) (*Identifier, error) {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return nil, err
+	}
+	ident, ok := node.(*Identifier)
+	if !ok {
+		return nil, fmt.Errorf("expected an Identifier, got %s", kindName(node))
+	}
+	return ident, nil
+}
+
+func asStmt(node Node) (Stmt, error) {
+	stmt, ok := node.(Stmt)
+	if !ok {
+		return nil, fmt.Errorf("expected a statement, got %s", kindName(node))
+	}
+	return stmt, nil
+}
+
+func operatorPiece(operator string) (lexer.Piece, error) {
+	kind, ok := lexer.Lookup(operator)
+	if !ok {
+		return lexer.Piece{}, fmt.Errorf("unknown operator %q", operator)
+	}
+	return lexer.Piece{Kind: kind, Value: operator}, nil
+}
+
+func keywordOf(datatype string) string {
+	switch datatype {
+	case "INTEGER":
+		return "yen"
+	case "STRING":
+		return "sol"
+	case "ARRAY":
+		return "varisai"
+	case "FLOAT":
+		return "thasamam"
+	case "HASH":
+		return "agarathi"
+	}
+	return datatype
+}
+
+// whole creates a piece covering the span.
+func whole(kind lexer.PieceType, value string, span Span) lexer.Piece {
+	return lexer.Piece{Kind: kind, Value: value, Pos: span.Start, End: span.End}
+}
+
+// atStart creates a piece of the given text starting the span.
+func atStart(kind lexer.PieceType, text string, span Span) lexer.Piece {
+	piece := lexer.Piece{Kind: kind, Value: text}
+	if span.IsValid() {
+		piece.Pos = span.Start
+		piece.End = lexer.Position{
+			Offset: span.Start.Offset + len(text),
+			Line:   span.Start.Line,
+			Column: span.Start.Column + utf8.RuneCountInString(text),
+		}
+	}
+	return piece
+}
+
+// atEnd creates a piece of the given text ending the span.
+func atEnd(kind lexer.PieceType, text string, span Span) lexer.Piece {
+	piece := lexer.Piece{Kind: kind, Value: text}
+	if span.IsValid() {
+		piece.End = span.End
+		piece.Pos = lexer.Position{
+			Offset: span.End.Offset - len(text),
+			Line:   span.End.Line,
+			Column: span.End.Column - utf8.RuneCountInString(text),
+		}
+	}
+	return piece
+}
@@ -0,0 +1,138 @@
+package tree_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/tree"
+)
+
+var roundTrips = []string{
+	`yen a = 10; sol s = "vanakkam";`,
+	`yen a = -(1 + 2) * 3; a = a % 4;`,
+	`sol s = "தமிழ்"; s neelam sollu; s[1] sollu;`,
+	`yen a kodu;`,
+	`sol name = "Name? " kodu; yen n = name + ": " கொடு;`,
+	`assert_equal(1 + 2, 3); assert_true(aam);`,
+	`varisai a = [1, "x", []]; a[0] sollu;`,
+	`thasamam f = 2.5 * 1.0; f sollu;`,
+	`"a", 1 + 2, [3] sollu; "b" solli; "c", "d" சொல்லி;`,
+	`"lib/math.n" irakkumathi; "வணக்கம்.n" இறக்குமதி;`,
+	"// first\nyen a = 1; // trailing\n// last",
+	"yen a = 1;\na < 2 endral {\n  a sollu;\n} illana a > 2 endral {\n  \"big\" sollu;\n} illana {\n}\n",
+	`yen i = 0; i < 3 varaikkum { i = i + 1; } 2 murai { aam && !illai sollu; }`,
+	`aam varaikkum { 2 murai { thodar; } aam endral { niruthu; } நிறுத்து; }`,
+	`aam endral { } illana illai endral { 1 sollu; } illana 1 > 2 என்றால் { } இல்லனா { 2 sollu; }`,
+	`aam endral { } illana "no" sollu; illai endral { } illana 1 > 2 endral { } illana a = 1;`,
+	`muyarchi { "x" eri; } pidi e { e sollu; } முயற்சி { } பிடி e { } iruthiyaga { 1 sollu; }`,
+	`"ab" ovvonrum l { l sollu; } range(0, 3) ஒவ்வொன்றும் i, n { niruthu; }`,
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, input := range roundTrips {
+		program := parse(input)
+		first, err := json.Marshal(program)
+		if err != nil {
+			t.Fatalf("%s: %s", input, err)
+		}
+		decoded := &tree.Program{}
+		if err := json.Unmarshal(first, decoded); err != nil {
+			t.Fatalf("%s: %s", input, err)
+		}
+		second, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatalf("%s: %s", input, err)
+		}
+		if string(first) != string(second) {
+			t.Errorf("%s: round trip changed the encoding\n%s\n%s", input, first, second)
+		}
+		if program.String() != decoded.String() {
+			t.Errorf("%s: round trip changed the tree\n%s\n%s", input, program, decoded)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	program := parse("yen a = 1 + 2;\na sollu;")
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"kind":"Program",` +
+		`"span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":22,"line":2,"column":8}},` +
+		`"statements":[{"kind":"Declaration",` +
+		`"span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":13,"line":1,"column":14}},` +
+		`"datatype":"INTEGER","name":"a",` +
+		`"nameSpan":{"start":{"offset":4,"line":1,"column":5},"end":{"offset":5,"line":1,"column":6}},` +
+		`"value":{"kind":"Binary",` +
+		`"span":{"start":{"offset":8,"line":1,"column":9},"end":{"offset":13,"line":1,"column":14}},` +
+		`"operator":"+",` +
+		`"left":{"kind":"Number","span":{"start":{"offset":8,"line":1,"column":9},"end":{"offset":9,"line":1,"column":10}},"value":1},` +
+		`"right":{"kind":"Number","span":{"start":{"offset":12,"line":1,"column":13},"end":{"offset":13,"line":1,"column":14}},"value":2}}},` +
+		`{"kind":"PrintStmt",` +
+		`"span":{"start":{"offset":15,"line":2,"column":1},"end":{"offset":22,"line":2,"column":8}},` +
+		`"values":[{"kind":"Identifier","span":{"start":{"offset":15,"line":2,"column":1},"end":{"offset":16,"line":2,"column":2}},"name":"a"}],"newline":true}],` +
+		`"comments":[]}`
+	if string(data) != expected {
+		t.Errorf("unexpected encoding\n%s\nwant\n%s", data, expected)
+	}
+}
+
+func TestJSONWithoutSpans(t *testing.T) {
+	input := `{"kind":"Program","statements":[
+		{"kind":"PrintStmt","values":[{"kind":"Binary","operator":"*",
+			"left":{"kind":"StringLiteral","value":"a"},
+			"right":{"kind":"Number","value":3}}]}]}`
+	program := &tree.Program{}
+	if err := json.Unmarshal([]byte(input), program); err != nil {
+		t.Fatal(err)
+	}
+	if program.String() != "print (a * 3)\n" {
+		t.Errorf("decoded %q", program.String())
+	}
+}
+
+func TestJSONElseIfStmt(t *testing.T) {
+	// an IfStmt as the else branch is read as the first of the else ifs
+	input := `{"kind":"Program","statements":[{"kind":"IfStmt",
+		"condition":{"kind":"Boolean","value":false},"then":{"kind":"Block","statements":[]},
+		"else":{"kind":"IfStmt","condition":{"kind":"Boolean","value":true},
+			"then":{"kind":"Block","statements":[]},
+			"else":{"kind":"IfStmt","condition":{"kind":"Boolean","value":false},
+				"then":{"kind":"Block","statements":[]},
+				"else":{"kind":"Block","statements":[]}}}}]}`
+	program := &tree.Program{}
+	if err := json.Unmarshal([]byte(input), program); err != nil {
+		t.Fatal(err)
+	}
+	stmt := program.Statements[0].(*tree.IfStmt)
+	if len(stmt.ElseIfs) != 2 || stmt.Else == nil {
+		t.Errorf("decoded %d else ifs and else %v", len(stmt.ElseIfs), stmt.Else)
+	}
+	if program.String() != "if false \nelse if true \nelse if false \nelse " {
+		t.Errorf("decoded %q", program.String())
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"kind":"Loop"}`, `unknown node kind "Loop"`},
+		{`{"kind":"Number","value":1}`, "expected a Program"},
+		{`{"kind":"Program","statements":[{"kind":"Number","value":1}]}`, "expected a statement, got Number"},
+		{`{"kind":"Program","statements":[{"kind":"PrintStmt","values":[{"kind":"Binary","operator":"^"}]}]}`, `unknown operator "^"`},
+		{`{"kind":"Program","statements":[{"kind":"IfStmt","condition":{"kind":"Boolean","value":true},` +
+			`"then":{"kind":"Block","statements":[]},"elseIfs":[{"kind":"Block","statements":[]}],"else":null}]}`, "expected an ElseIf, got Block"},
+		{`{"kind":"Program","statements":[{"kind":"IfStmt","condition":{"kind":"Boolean","value":true},` +
+			`"then":{"kind":"Block","statements":[]},"else":{"kind":"Number","value":1}}]}`, "expected a Block or an IfStmt, got Number"},
+	}
+	for _, tt := range tests {
+		err := json.Unmarshal([]byte(tt.input), &tree.Program{})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tree
+
+import "github.com/iam-naveen/compiler/lexer"
+
+// Span is the region of source covered by a node.
+type Span struct {
+	Start lexer.Position `json:"start"`
+	End   lexer.Position `json:"end"`
+}
+
+func (s Span) IsValid() bool {
+	return s.Start.IsValid()
+}
+
+// Contains reports whether pos lies within the span.
+func (s Span) Contains(pos lexer.Position) bool {
+	return s.IsValid() && s.Start.Offset <= pos.Offset && pos.Offset <= s.End.Offset
+}
+
+// SpanOf returns the span from the first to the last piece of the node
+// and its children. Pieces without a position, like the ones created by
+// the optimizer, are skipped.
+func SpanOf(node Node) Span {
+	span := Span{}
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		for _, piece := range pieces(n) {
+			if !piece.Pos.IsValid() {
+				continue
+			}
+			if !span.Start.IsValid() || piece.Pos.Offset < span.Start.Offset {
+				span.Start = piece.Pos
+			}
+			if !span.End.IsValid() || piece.End.Offset > span.End.Offset {
+				span.End = piece.End
+			}
+		}
+		return true
+	})
+	return span
+}
+
+// pieces returns the pieces held by the node itself, not its children.
+func pieces(node Node) []lexer.Piece {
+	switch n := node.(type) {
+	case *Identifier:
+		return []lexer.Piece{n.Piece}
+	case *Number:
+		return []lexer.Piece{n.Piece}
+	case *Float:
+		return []lexer.Piece{n.Piece}
+	case *StringLiteral:
+		return []lexer.Piece{n.Piece}
+	case *Boolean:
+		return []lexer.Piece{n.Piece}
+	case *Array:
+		return []lexer.Piece{n.Piece, n.Close}
+	case *Access:
+		return []lexer.Piece{n.Piece, n.Close}
+	case *Binary:
+		return []lexer.Piece{n.Operator}
+	case *Prefix:
+		return []lexer.Piece{n.Operator}
+	case *Print:
+		return []lexer.Piece{n.Piece}
+	case *Input:
+		return []lexer.Piece{n.Piece}
+	case *Length:
+		return []lexer.Piece{n.Piece}
+	case *Call:
+		return []lexer.Piece{n.Close}
+	case *If:
+		return []lexer.Piece{n.Piece}
+	case *Else:
+		return []lexer.Piece{n.Piece}
+	case *Block:
+		return []lexer.Piece{n.Piece, n.Close}
+	case *Declaration:
+		return []lexer.Piece{n.Piece, n.Name}
+	case *IfStmt:
+		return []lexer.Piece{n.Piece}
+	case *ElseIf:
+		return []lexer.Piece{n.Else, n.Piece}
+	case *WhileStmt:
+		return []lexer.Piece{n.Piece}
+	case *ForStmt:
+		return []lexer.Piece{n.Piece}
+	case *EachStmt:
+		return []lexer.Piece{n.Piece}
+	case *PrintStmt:
+		return []lexer.Piece{n.Piece}
+	case *ImportStmt:
+		return []lexer.Piece{n.Piece}
+	case *BranchStmt:
+		return []lexer.Piece{n.Piece}
+	case *TryStmt:
+		return []lexer.Piece{n.Piece, n.Catch}
+	case *RaiseStmt:
+		return []lexer.Piece{n.Piece}
+	case *Function:
+		return []lexer.Piece{n.Name, n.Return}
+	case *ReturnStmt:
+		return []lexer.Piece{n.Piece}
+	case *Comment:
+		return []lexer.Piece{n.Piece}
+	}
+	return nil
+}
@@ -13,28 +13,33 @@ import (
 // =====================================
 
 type Program struct {
-	Children []Stmt
+	Statements []Stmt
+	Comments   []*Comment // in source order, not part of Children
 }
 
 func (b *Program) String() string {
 	var out string
-	for _, s := range b.Children {
+	for _, s := range b.Statements {
 		out += fmt.Sprintf("%v", s)
 	}
 	return out
 }
 
+func (b *Program) Children() []Node {
+	return stmtNodes(b.Statements)
+}
+
 func (b *Program) Stmt() {}
 
 func (s Program) Print(level int, prefix, out string) string {
-	if len(s.Children) == 0 {
+	if len(s.Statements) == 0 {
 		return out
 	}
-	for i, stmt := range s.Children {
-		if i == len(s.Children)-1 {
-			out += stmt.print(level, prefix+Last, "", true)
+	for i, stmt := range s.Statements {
+		if i == len(s.Statements)-1 {
+			out += printNode(stmt, level, prefix+Last, "", true)
 		} else {
-			out += stmt.print(level, prefix+Tee, "", false)
+			out += printNode(stmt, level, prefix+Tee, "", false)
 		}
 	}
 	return out
@@ -45,31 +50,36 @@ func (s Program) Print(level int, prefix, out string) string {
 // =====================================
 
 type Block struct {
-	Piece    lexer.Piece
-	Children []Stmt
+	Piece      lexer.Piece
+	Statements []Stmt
+	Close      lexer.Piece
 }
 
 func (b *Block) String() string {
 	var out string
-	for _, s := range b.Children {
+	for _, s := range b.Statements {
 		out += fmt.Sprintf("%v", s)
 	}
 	return out
 }
 
+func (b *Block) Children() []Node {
+	return stmtNodes(b.Statements)
+}
+
 func (b *Block) Stmt() {}
 
 func (b *Block) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, "{}")
-	if len(b.Children) == 0 {
+	if len(b.Statements) == 0 {
 		return out
 	}
 	margin := strings.Repeat(pipe+indent, level+1)
-	for i, stmt := range b.Children {
-		if i == len(b.Children)-1 {
-			out += stmt.print(level+1, Last, margin, true)
+	for i, stmt := range b.Statements {
+		if i == len(b.Statements)-1 {
+			out += printNode(stmt, level+1, Last, margin, true)
 		} else {
-			out += stmt.print(level+1, Tee, margin, false)
+			out += printNode(stmt, level+1, Tee, margin, false)
 		}
 	}
 	return out
@@ -87,10 +97,14 @@ func (e *ExpressionStmt) String() string {
 	return fmt.Sprintf("%v\n", e.Expression)
 }
 
+func (e *ExpressionStmt) Children() []Node {
+	return []Node{e.Expression}
+}
+
 func (e *ExpressionStmt) Stmt() {}
 
 func (s *ExpressionStmt) print(level int, prefix, out string, last bool) string {
-	out += s.Expression.print(level, prefix, "", last)
+	out += printNode(s.Expression, level, prefix, "", last)
 	return out
 }
 
@@ -99,6 +113,7 @@ func (s *ExpressionStmt) print(level int, prefix, out string, last bool) string
 // =====================================
 
 type Declaration struct {
+	Piece    lexer.Piece
 	Datatype string
 	Name     lexer.Piece
 	Value    Expr
@@ -107,12 +122,19 @@ type Declaration struct {
 func (v *Declaration) String() string {
 	return fmt.Sprintf("%s %s %s\n", v.Datatype, v.Name.Value, v.Value)
 }
+
+func (v *Declaration) Children() []Node {
+	if v.Value == nil {
+		return nil
+	}
+	return []Node{v.Value}
+}
 func (v *Declaration) Stmt() {}
 
 func (s *Declaration) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s %s\n", prefix, s.Name.Value)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Value.print(level+1, Last, margin, true)
+	out += printNode(s.Value, level+1, Last, margin, true)
 	return out
 }
 
@@ -124,29 +146,83 @@ type IfStmt struct {
 	Piece     lexer.Piece
 	Condition Expr
 	Then      *Block
-	Else      Stmt
+	ElseIfs   []*ElseIf // tried in order when the condition is false
+	Else      *Block    // nil when there is no illana block
 }
 
 func (i *IfStmt) String() string {
 	out := fmt.Sprintf("if %v %v\n", i.Condition, i.Then)
+	for _, elseIf := range i.ElseIfs {
+		out += elseIf.String()
+	}
 	if i.Else != nil {
 		out += fmt.Sprintf("else %v", i.Else)
 	}
 	return out
 }
 
+func (i *IfStmt) Children() []Node {
+	nodes := []Node{i.Condition, i.Then}
+	for _, elseIf := range i.ElseIfs {
+		nodes = append(nodes, elseIf)
+	}
+	if i.Else != nil {
+		nodes = append(nodes, i.Else)
+	}
+	return nodes
+}
+
 func (i *IfStmt) Stmt() {}
 
 func (s *IfStmt) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s if %s\n", prefix, s.Condition)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Then.print(level+1, Tee, margin, false)
+	more := len(s.ElseIfs) > 0 || s.Else != nil
+	out += s.Then.print(level+1, branchPrefix(more), margin, !more)
+	for n, elseIf := range s.ElseIfs {
+		more = n < len(s.ElseIfs)-1 || s.Else != nil
+		out += elseIf.print(level+1, branchPrefix(more), margin, !more)
+	}
 	if s.Else != nil {
-		out += s.Else.print(level+1, prefix, margin, true)
+		out += margin + Last + " else\n"
+		out += s.Else.print(level+2, Last, strings.Repeat(pipe+indent, level+2), true)
 	}
 	return out
 }
 
+// branchPrefix is the prefix of a branch of an if statement, more telling
+// whether other branches follow it.
+func branchPrefix(more bool) string {
+	if more {
+		return Tee
+	}
+	return Last
+}
+
+// ElseIf is an illana ... endral branch of an IfStmt, its block runs
+// when the branches before it were not taken and its condition is true.
+type ElseIf struct {
+	Else      lexer.Piece // illana
+	Piece     lexer.Piece // endral
+	Condition Expr
+	Then      *Block
+}
+
+func (e *ElseIf) String() string {
+	return fmt.Sprintf("else if %v %v\n", e.Condition, e.Then)
+}
+
+func (e *ElseIf) Children() []Node {
+	return []Node{e.Condition, e.Then}
+}
+
+func (s *ElseIf) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s else if %s\n", prefix, s.Condition)
+	margin := strings.Repeat(pipe+indent, level+1)
+	out += s.Then.print(level+1, Last, margin, true)
+	return out
+}
+
 // =====================================
 // ======== WHILE STATEMENT ============
 // =====================================
@@ -161,6 +237,10 @@ func (w *WhileStmt) String() string {
 	return fmt.Sprintf("while %v %v\n", w.Condition, w.Body)
 }
 
+func (w *WhileStmt) Children() []Node {
+	return []Node{w.Condition, w.Body}
+}
+
 func (w *WhileStmt) Stmt() {}
 
 func (s *WhileStmt) print(level int, prefix, out string, last bool) string {
@@ -184,6 +264,10 @@ func (f *ForStmt) String() string {
 	return fmt.Sprintf("for %v %v\n", f.Count, f.Body)
 }
 
+func (f *ForStmt) Children() []Node {
+	return []Node{f.Count, f.Body}
+}
+
 func (f *ForStmt) Stmt() {}
 
 func (s *ForStmt) print(level int, prefix, out string, last bool) string {
@@ -193,25 +277,240 @@ func (s *ForStmt) print(level int, prefix, out string, last bool) string {
 	return out
 }
 
+// =====================================
+// ======== EACH STATEMENT =============
+// =====================================
+
+// EachStmt runs Body for each letter of a string, element of an array,
+// key of a hash or number of a range, bound to Element in a new scope.
+// Index, when given, is bound to the position, or for a hash to the key
+// while Element is bound to the value.
+type EachStmt struct {
+	Piece    lexer.Piece
+	Iterable Expr
+	Index    *Identifier
+	Element  Identifier
+	Body     *Block
+}
+
+func (e *EachStmt) names() string {
+	if e.Index != nil {
+		return e.Index.Name + ", " + e.Element.Name
+	}
+	return e.Element.Name
+}
+
+func (e *EachStmt) String() string {
+	return fmt.Sprintf("for each %s in %v %v\n", e.names(), e.Iterable, e.Body)
+}
+
+func (e *EachStmt) Children() []Node {
+	nodes := []Node{e.Iterable}
+	if e.Index != nil {
+		nodes = append(nodes, e.Index)
+	}
+	return append(nodes, &e.Element, e.Body)
+}
+
+func (e *EachStmt) Stmt() {}
+
+func (s *EachStmt) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s each %s in %s\n", prefix, s.names(), s.Iterable)
+	margin := strings.Repeat(pipe+indent, level+1)
+	out += s.Body.print(level+1, Tee, margin, true)
+	return out
+}
+
 // =====================================
 // ======== PRINT STATEMENT ============
 // =====================================
 
+// PrintStmt writes its values separated by spaces, ended by a newline
+// unless the keyword is solli.
 type PrintStmt struct {
-	Piece lexer.Piece
-	Value Expr
+	Piece  lexer.Piece
+	Values []Expr
+}
+
+// Newline reports whether the statement ends the line it writes.
+func (p *PrintStmt) Newline() bool {
+	return lexer.Tanglish(p.Piece.Value) != "solli"
+}
+
+func (p *PrintStmt) verb() string {
+	if p.Newline() {
+		return "print"
+	}
+	return "print without newline"
 }
 
 func (p *PrintStmt) String() string {
-	return fmt.Sprintf("print %v\n", p.Value)
+	values := []string{}
+	for _, value := range p.Values {
+		values = append(values, value.String())
+	}
+	return fmt.Sprintf("%s %s\n", p.verb(), strings.Join(values, ", "))
+}
+
+func (p *PrintStmt) Children() []Node {
+	children := []Node{}
+	for _, value := range p.Values {
+		children = append(children, value)
+	}
+	return children
 }
 
 func (p *PrintStmt) Stmt() {}
 
 func (s *PrintStmt) print(level int, prefix, out string, last bool) string {
-	out += fmt.Sprintf("%s %s\n", prefix, "print")
+	out += fmt.Sprintf("%s %s\n", prefix, s.verb())
+	margin := strings.Repeat(pipe+indent, level+1)
+	for n, value := range s.Values {
+		if n == len(s.Values)-1 {
+			out += printNode(value, level+1, Last, margin, true)
+		} else {
+			out += printNode(value, level+1, Tee, margin, false)
+		}
+	}
+	return out
+}
+
+// =====================================
+// ======== BRANCH STATEMENT ===========
+// =====================================
+
+// BranchStmt leaves the innermost loop with niruthu, or goes on with its
+// next turn with thodar.
+type BranchStmt struct {
+	Piece lexer.Piece
+}
+
+// Break reports whether the statement leaves the loop.
+func (b *BranchStmt) Break() bool {
+	return lexer.Tanglish(b.Piece.Value) == "niruthu"
+}
+
+func (b *BranchStmt) verb() string {
+	if b.Break() {
+		return "break"
+	}
+	return "continue"
+}
+
+func (b *BranchStmt) String() string {
+	return b.verb() + "\n"
+}
+
+func (b *BranchStmt) Children() []Node {
+	return nil
+}
+
+func (b *BranchStmt) Stmt() {}
+
+func (s *BranchStmt) print(level int, prefix, out string, last bool) string {
+	return out + fmt.Sprintf("%s %s\n", prefix, s.verb())
+}
+
+// =====================================
+// ========= TRY STATEMENT =============
+// =====================================
+
+// TryStmt runs Body, an error stopping it runs Handler with the error
+// bound to Name. Finally runs after them however they end.
+type TryStmt struct {
+	Piece   lexer.Piece // muyarchi
+	Body    *Block
+	Catch   lexer.Piece // pidi
+	Name    Identifier
+	Handler *Block
+	Finally *Block // nil when there is no iruthiyaga block
+}
+
+func (t *TryStmt) String() string {
+	out := fmt.Sprintf("try %v\ncatch %s %v\n", t.Body, t.Name.Name, t.Handler)
+	if t.Finally != nil {
+		out += fmt.Sprintf("finally %v\n", t.Finally)
+	}
+	return out
+}
+
+func (t *TryStmt) Children() []Node {
+	nodes := []Node{t.Body, &t.Name, t.Handler}
+	if t.Finally != nil {
+		nodes = append(nodes, t.Finally)
+	}
+	return nodes
+}
+
+func (t *TryStmt) Stmt() {}
+
+func (s *TryStmt) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s try\n", prefix)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Value.print(level+1, Last, margin, true)
+	inner := strings.Repeat(pipe+indent, level+2)
+	out += s.Body.print(level+1, Tee, margin, false)
+	out += margin + branchPrefix(s.Finally != nil) + " catch " + s.Name.Name + "\n"
+	out += s.Handler.print(level+2, Last, inner, true)
+	if s.Finally != nil {
+		out += margin + Last + " finally\n"
+		out += s.Finally.print(level+2, Last, inner, true)
+	}
+	return out
+}
+
+// =====================================
+// ======== RAISE STATEMENT ============
+// =====================================
+
+// RaiseStmt stops the program with an error, or hands it to the pidi of
+// the muyarchi around it, with Value as the message.
+type RaiseStmt struct {
+	Piece lexer.Piece
+	Value Expr
+}
+
+func (r *RaiseStmt) String() string {
+	return fmt.Sprintf("raise %v\n", r.Value)
+}
+
+func (r *RaiseStmt) Children() []Node {
+	return []Node{r.Value}
+}
+
+func (r *RaiseStmt) Stmt() {}
+
+func (s *RaiseStmt) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s raise\n", prefix)
+	margin := strings.Repeat(pipe+indent, level+1)
+	out += printNode(s.Value, level+1, Last, margin, true)
+	return out
+}
+
+// =====================================
+// ======== IMPORT STATEMENT ===========
+// =====================================
+
+// ImportStmt runs the file at Path, relative to the importing file, and
+// binds the names it exports.
+type ImportStmt struct {
+	Piece lexer.Piece
+	Path  StringLiteral
+}
+
+func (i *ImportStmt) String() string {
+	return fmt.Sprintf("import %q\n", i.Path.Value)
+}
+
+func (i *ImportStmt) Children() []Node {
+	return []Node{&i.Path}
+}
+
+func (i *ImportStmt) Stmt() {}
+
+func (s *ImportStmt) print(level int, prefix, out string, last bool) string {
+	out += fmt.Sprintf("%s %s\n", prefix, "import")
+	margin := strings.Repeat(pipe+indent, level+1)
+	out += printNode(&s.Path, level+1, Last, margin, true)
 	return out
 }
 
@@ -240,6 +539,14 @@ func (f *Function) String() string {
 	return out.String()
 }
 
+func (f *Function) Children() []Node {
+	nodes := []Node{}
+	for _, arg := range f.Args {
+		nodes = append(nodes, arg)
+	}
+	return append(nodes, f.Body)
+}
+
 func (f *Function) Stmt() {}
 
 func (s *Function) print(level int, prefix, out string, last bool) string {
@@ -260,11 +567,35 @@ func (r *ReturnStmt) String() string {
 	return fmt.Sprintf("return %v\n", r.Value)
 }
 
+func (r *ReturnStmt) Children() []Node {
+	if r.Value == nil {
+		return nil
+	}
+	return []Node{r.Value}
+}
+
 func (r *ReturnStmt) Stmt() {}
 
 func (s *ReturnStmt) print(level int, prefix, out string, last bool) string {
 	out += fmt.Sprintf("%s return\n", prefix)
 	margin := strings.Repeat(pipe+indent, level+1)
-	out += s.Value.print(level+1, Last, margin, true)
+	out += printNode(s.Value, level+1, Last, margin, true)
 	return out
 }
+
+// =====================================
+// ============= COMMENT ===============
+// =====================================
+
+type Comment struct {
+	Piece lexer.Piece
+	Text  string // including the leading //
+}
+
+func (c *Comment) String() string {
+	return c.Text
+}
+
+func (c *Comment) Children() []Node {
+	return nil
+}
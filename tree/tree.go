@@ -1,25 +1,41 @@
 package tree
 
+import "fmt"
+
 type Node interface {
 	String() string
+	Children() []Node
 }
 
 type Stmt interface {
 	Node
 	Stmt()
-	print(level int, prefix, out string, prev bool) string
 }
 
 type Expr interface {
 	Node
 	Expr()
+}
+
+// printer is implemented by the nodes of this package to draw themselves
+// in Program.Print.
+type printer interface {
 	print(level int, prefix, out string, last bool) string
 }
 
+// printNode draws node, falling back to its String form for nodes
+// defined outside this package.
+func printNode(node Node, level int, prefix, out string, last bool) string {
+	if p, ok := node.(printer); ok {
+		return p.print(level, prefix, out, last)
+	}
+	return out + fmt.Sprintf("%s %s\n", prefix, node)
+}
+
 const (
 	indent = "   "
-	pipe = "│"
-	Tee = "├──"
-	Last = "└──"
-	line = "─"
+	pipe   = "│"
+	Tee    = "├──"
+	Last   = "└──"
+	line   = "─"
 )
@@ -0,0 +1,61 @@
+package tree_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintIfChains(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`aam endral { 1 sollu; }`, []string{
+			"└── if true",
+			"│   └── {}",
+			"│   │   └── print",
+			"│   │   │   └── 1",
+		}},
+		{`aam endral { } illana { 2 sollu; }`, []string{
+			"└── if true",
+			"│   ├── {}",
+			"│   └── else",
+			"│   │   └── {}",
+			"│   │   │   └── print",
+			"│   │   │   │   └── 2",
+		}},
+		{`aam endral { } illana illai endral { }`, []string{
+			"└── if true",
+			"│   ├── {}",
+			"│   └── else if false",
+			"│   │   └── {}",
+		}},
+		{`aam endral { } illana illai endral { 1 sollu; } illana aam endral { } illana { }`, []string{
+			"└── if true",
+			"│   ├── {}",
+			"│   ├── else if false",
+			"│   │   └── {}",
+			"│   │   │   └── print",
+			"│   │   │   │   └── 1",
+			"│   ├── else if true",
+			"│   │   └── {}",
+			"│   └── else",
+			"│   │   └── {}",
+		}},
+		// an if inside a block is not an else if
+		{`aam endral { } illana { illai endral { } }`, []string{
+			"└── if true",
+			"│   ├── {}",
+			"│   └── else",
+			"│   │   └── {}",
+			"│   │   │   └── if false",
+			"│   │   │   │   └── {}",
+		}},
+	}
+	for _, tt := range tests {
+		got := parse(tt.input).Print(0, "", "")
+		if want := strings.Join(tt.expected, "\n") + "\n"; got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.input, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tree
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order, visiting
+// the children in the order returned by Children.
+func Walk(node Node, v Visitor) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range node.Children() {
+		Walk(child, v)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in depth-first order, calling
+// f for each node. If f returns true, Inspect continues with the children
+// of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, inspector(f))
+}
+
+// Rewrite replaces every node of the tree rooted at node, bottom-up, with
+// the result of f, and returns the new root. f sees a node after its
+// children were rewritten. A nil result drops a statement from its
+// Program or Block, clears an optional field (an else branch, a declared
+// value or a prompt) and empties the handler of a try statement; other
+// fields must be given a node of the same kind.
+func Rewrite(node Node, f func(Node) Node) Node {
+	switch n := node.(type) {
+	case *Program:
+		n.Statements = rewriteStmts(n.Statements, f)
+	case *Block:
+		n.Statements = rewriteStmts(n.Statements, f)
+	case *ExpressionStmt:
+		n.Expression = rewriteExpr(n.Expression, f)
+	case *Declaration:
+		if n.Value != nil {
+			n.Value = rewriteOptionalExpr(n.Value, f)
+		}
+	case *Input:
+		if n.Prompt != nil {
+			n.Prompt = rewriteOptionalExpr(n.Prompt, f)
+		}
+	case *IfStmt:
+		n.Condition = rewriteExpr(n.Condition, f)
+		n.Then = Rewrite(n.Then, f).(*Block)
+		for k, elseIf := range n.ElseIfs {
+			n.ElseIfs[k] = Rewrite(elseIf, f).(*ElseIf)
+		}
+		if n.Else != nil {
+			n.Else = rewriteOptionalBlock(n.Else, f)
+		}
+	case *ElseIf:
+		n.Condition = rewriteExpr(n.Condition, f)
+		n.Then = Rewrite(n.Then, f).(*Block)
+	case *WhileStmt:
+		n.Condition = rewriteExpr(n.Condition, f)
+		n.Body = Rewrite(n.Body, f).(*Block)
+	case *ForStmt:
+		n.Count = rewriteExpr(n.Count, f)
+		n.Body = Rewrite(n.Body, f).(*Block)
+	case *EachStmt:
+		n.Iterable = rewriteExpr(n.Iterable, f)
+		n.Body = Rewrite(n.Body, f).(*Block)
+	case *TryStmt:
+		n.Body = Rewrite(n.Body, f).(*Block)
+		// the handler stays, an empty one still catches the errors
+		if n.Handler = rewriteOptionalBlock(n.Handler, f); n.Handler == nil {
+			n.Handler = &Block{}
+		}
+		if n.Finally != nil {
+			n.Finally = rewriteOptionalBlock(n.Finally, f)
+		}
+	case *RaiseStmt:
+		n.Value = rewriteExpr(n.Value, f)
+	case *PrintStmt:
+		for i, value := range n.Values {
+			n.Values[i] = rewriteExpr(value, f)
+		}
+	case *Function:
+		for i, arg := range n.Args {
+			n.Args[i] = rewriteExpr(arg, f)
+		}
+		n.Body = Rewrite(n.Body, f).(*Block)
+	case *ReturnStmt:
+		if n.Value != nil {
+			n.Value = rewriteOptionalExpr(n.Value, f)
+		}
+	case *Array:
+		for i, element := range n.Elements {
+			n.Elements[i] = rewriteExpr(element, f)
+		}
+	case *Access:
+		n.Left = rewriteExpr(n.Left, f)
+		n.Index = rewriteExpr(n.Index, f)
+	case *Binary:
+		n.Left = rewriteExpr(n.Left, f)
+		n.Right = rewriteExpr(n.Right, f)
+	case *Assign:
+		n.Right = rewriteExpr(n.Right, f)
+	case *Prefix:
+		n.Right = rewriteExpr(n.Right, f)
+	case *Print:
+		n.Value = rewriteExpr(n.Value, f)
+	case *Length:
+		n.Value = rewriteExpr(n.Value, f)
+	case *Call:
+		for i, arg := range n.Args {
+			n.Args[i] = rewriteExpr(arg, f)
+		}
+	case *If:
+		n.Condition = rewriteExpr(n.Condition, f)
+		n.Body = Rewrite(n.Body, f).(*Block)
+		if n.Alternate != nil {
+			n.Alternate = Rewrite(n.Alternate, f).(*Block)
+		}
+	}
+	return f(node)
+}
+
+func rewriteStmts(stmts []Stmt, f func(Node) Node) []Stmt {
+	out := []Stmt{}
+	for _, stmt := range stmts {
+		if stmt = rewriteOptionalStmt(stmt, f); stmt != nil {
+			out = append(out, stmt)
+		}
+	}
+	return out
+}
+
+func rewriteOptionalStmt(stmt Stmt, f func(Node) Node) Stmt {
+	if node := Rewrite(stmt, f); node != nil {
+		return node.(Stmt)
+	}
+	return nil
+}
+
+func rewriteOptionalBlock(block *Block, f func(Node) Node) *Block {
+	if node := Rewrite(block, f); node != nil {
+		return node.(*Block)
+	}
+	return nil
+}
+
+func rewriteExpr(expr Expr, f func(Node) Node) Expr {
+	return Rewrite(expr, f).(Expr)
+}
+
+func rewriteOptionalExpr(expr Expr, f func(Node) Node) Expr {
+	if node := Rewrite(expr, f); node != nil {
+		return node.(Expr)
+	}
+	return nil
+}
+
+func stmtNodes(stmts []Stmt) []Node {
+	nodes := make([]Node, len(stmts))
+	for i, stmt := range stmts {
+		nodes[i] = stmt
+	}
+	return nodes
+}
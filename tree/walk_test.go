@@ -0,0 +1,158 @@
+package tree_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+)
+
+func parse(input string) *tree.Program {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return parser.Parse(channel, false)
+}
+
+const program = `yen a = 1 + 2;
+a < 3 endral { a sollu; } illana { a = a - 1; }
+a varaikkum { 2 murai { "x" sollu; } }`
+
+func TestInspect(t *testing.T) {
+	var visited []string
+	tree.Inspect(parse(program), func(node tree.Node) bool {
+		if node != nil {
+			visited = append(visited, fmt.Sprintf("%T", node))
+		}
+		return true
+	})
+	expected := []string{
+		"*tree.Program",
+		"*tree.Declaration", "*tree.Binary", "*tree.Number", "*tree.Number",
+		"*tree.IfStmt", "*tree.Binary", "*tree.Identifier", "*tree.Number",
+		"*tree.Block", "*tree.PrintStmt", "*tree.Identifier",
+		"*tree.Block", "*tree.ExpressionStmt", "*tree.Assign", "*tree.Identifier",
+		"*tree.Binary", "*tree.Identifier", "*tree.Number",
+		"*tree.WhileStmt", "*tree.Identifier",
+		"*tree.Block", "*tree.ForStmt", "*tree.Number",
+		"*tree.Block", "*tree.PrintStmt", "*tree.StringLiteral",
+	}
+	if strings.Join(visited, " ") != strings.Join(expected, " ") {
+		t.Errorf("visited\n%v\nwant\n%v", visited, expected)
+	}
+}
+
+func TestInspectPrunes(t *testing.T) {
+	count := 0
+	tree.Inspect(parse(program), func(node tree.Node) bool {
+		if node != nil {
+			count++
+		}
+		_, isBlock := node.(*tree.Block)
+		return !isBlock
+	})
+	// the if and while blocks are visited but not their contents
+	if count != 14 {
+		t.Errorf("visited %d nodes, want 14", count)
+	}
+}
+
+type depth struct {
+	level, max *int
+}
+
+func (d depth) Visit(node tree.Node) tree.Visitor {
+	if node == nil {
+		*d.level--
+		return nil
+	}
+	*d.level++
+	if *d.level > *d.max {
+		*d.max = *d.level
+	}
+	return d
+}
+
+func TestWalk(t *testing.T) {
+	level, max := 0, 0
+	tree.Walk(parse(program), depth{&level, &max})
+	if level != 0 {
+		t.Errorf("unbalanced Visit(nil) calls, level %d", level)
+	}
+	// Program > While > Block > For > Block > Print > String
+	if max != 7 {
+		t.Errorf("max depth %d, want 7", max)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	ast := parse(program)
+	tree.Rewrite(ast, func(node tree.Node) tree.Node {
+		switch node := node.(type) {
+		case *tree.PrintStmt:
+			return nil
+		case *tree.Number:
+			return &tree.Number{Value: node.Value * 10}
+		}
+		return node
+	})
+	tree.Inspect(ast, func(node tree.Node) bool {
+		switch node := node.(type) {
+		case *tree.PrintStmt:
+			t.Errorf("print statement was not removed")
+		case *tree.Number:
+			if node.Value%10 != 0 {
+				t.Errorf("number %d was not rewritten", node.Value)
+			}
+		}
+		return true
+	})
+	then := ast.Statements[1].(*tree.IfStmt).Then
+	if len(then.Statements) != 0 {
+		t.Errorf("expected empty then block, got %v", then.Statements)
+	}
+}
+
+func TestRewriteDropsEmptyBlocks(t *testing.T) {
+	ast := parse(`muyarchi { "a" eri; } pidi e { } iruthiyaga { }`)
+	tree.Rewrite(ast, func(node tree.Node) tree.Node {
+		if block, ok := node.(*tree.Block); ok && len(block.Statements) == 0 {
+			return nil
+		}
+		return node
+	})
+	try := ast.Statements[0].(*tree.TryStmt)
+	if try.Handler == nil || len(try.Handler.Statements) != 0 {
+		t.Errorf("expected an empty handler, got %v", try.Handler)
+	}
+	if try.Finally != nil {
+		t.Errorf("expected the finally block to be dropped, got %v", try.Finally)
+	}
+}
+
+// external nodes can be mixed into the tree and are printed with String.
+type comment struct{ text string }
+
+func (c *comment) String() string        { return "// " + c.text }
+func (c *comment) Children() []tree.Node { return nil }
+func (c *comment) Stmt()                 {}
+
+func TestExternalNode(t *testing.T) {
+	ast := parse(`yen a = 1;`)
+	ast.Statements = append(ast.Statements, &comment{"done"})
+	out := ast.Print(0, "", "")
+	if !strings.Contains(out, "└── // done") {
+		t.Errorf("external node not printed:\n%s", out)
+	}
+	count := 0
+	tree.Inspect(ast, func(node tree.Node) bool {
+		if node != nil {
+			count++
+		}
+		return true
+	})
+	if count != 4 {
+		t.Errorf("visited %d nodes, want 4", count)
+	}
+}
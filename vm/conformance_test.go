@@ -0,0 +1,171 @@
+package vm_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/iam-naveen/compiler/compiler"
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+	"github.com/iam-naveen/compiler/parser"
+	"github.com/iam-naveen/compiler/tree"
+	"github.com/iam-naveen/compiler/vm"
+)
+
+// Every program is run by both backends and their output compared.
+var conformance = []struct {
+	name   string
+	input  string
+	output string
+}{
+	{"integers", `yen a = 7; yen b = 3; a + b sollu; a - b sollu; a * b sollu; a / b sollu; a % b sollu; yen c = -a; c sollu;`,
+		"10\n4\n21\n2\n1\n-7\n"},
+	{"precedence", `1 + 2 * 3 - 4 / 2 sollu;`, "5\n"},
+	{"strings", `sol s = "vanakkam"; s sollu; s neelam sollu; s[2] sollu; s + 1 sollu; 2 + s sollu;`,
+		"vanakkam\n8\nn\nvanakkam1\n2vanakkam\n"},
+	{"comparisons", `yen a = 2; a < 3 sollu; a > 3 sollu; a <= 2 sollu; a >= 3 sollu; a == 2 sollu; a != 2 sollu;`,
+		"true\nfalse\ntrue\nfalse\ntrue\nfalse\n"},
+	{"logical", `aam && illai sollu; aam || illai sollu; aam && !illai sollu;`, "false\ntrue\ntrue\n"},
+	{"assignment", `yen a = 1; a = a + 41; a sollu;`, "42\n"},
+	{"if", `yen a = 1; a == 1 endral { "one" sollu; } illana { "other" sollu; }`, "one\n"},
+	{"else", `yen a = 2; a == 1 endral { "one" sollu; } illana { "other" sollu; }`, "other\n"},
+	{"else if", `yen a = 2; a == 1 endral { "one" sollu; } illana a == 2 endral { "two" sollu; } illana { "other" sollu; }`,
+		"two\n"},
+	{"else if chain", `yen a = 3; 4 murai { a == 1 endral { "one" sollu; } illana a == 2 endral { "two" sollu; } illana a == 3 endral { "three" sollu; } a = a - 1; }`,
+		"three\ntwo\none\n"},
+	{"non boolean else if", `illai endral { "a" sollu; } illana 1 endral { "b" sollu; } illana { "c" sollu; } "done" sollu;`,
+		"ERROR: Non Boolean Expression in If Statement\ndone\n"},
+	{"while", `yen i = 0; i < 3 varaikkum { i sollu; i = i + 1; }`, "0\n1\n2\n"},
+	{"for", `yen n = 2; n + 1 murai { "hi" sollu; }`, "hi\nhi\nhi\n"},
+	{"nested loops", `yen i = 0; 2 murai { 2 murai { i = i + 1; } } i sollu;`, "4\n"},
+	{"for count evaluated once", `yen n = 2; n murai { n = n + 1; } n sollu;`, "4\n"},
+	{"unknown identifier", `x sollu;`, "ERROR: Unknown identifier\n"},
+	{"index out of range", `sol s = "a"; s[3] sollu;`, "ERROR: Index out of range\n"},
+	{"non boolean condition", `1 endral { "yes" sollu; } illana { "no" sollu; } "done" sollu;`,
+		"ERROR: Non Boolean Expression in If Statement\ndone\n"},
+	{"print lists", `yen a = 2; "a is", a, a * 2 sollu; "x" solli; "y", a solli; "" sollu; a, aam sollu;`,
+		"a is 2 4\nxy 2\n2 true\n"},
+	{"break", `yen i = 0; aam varaikkum { i = i + 1; i == 3 endral { niruthu; } } i sollu;`, "3\n"},
+	{"continue", `yen i = 0; i < 5 varaikkum { i = i + 1; i % 2 == 0 endral { thodar; } i sollu; }`, "1\n3\n5\n"},
+	{"break in murai", `yen n = 0; 10 murai { n = n + 1; n == 4 endral { niruthu; } } n sollu; 2 murai { "after" sollu; }`,
+		"4\nafter\nafter\n"},
+	{"continue in murai", `yen n = 0; 4 murai { n = n + 1; n == 2 endral { thodar; } n sollu; }`, "1\n3\n4\n"},
+	{"nested break", `yen i = 0; i < 2 varaikkum { i = i + 1; 5 murai { "in" sollu; niruthu; "never" sollu; } 3 murai { thodar; } "out" sollu; }`,
+		"in\nout\nin\nout\n"},
+	{"expression statements are ignored", `yen a = 1; a + 1; a sollu;`, "1\n"},
+	{"failing expression statements are ignored", `yen a = 1; a / 0; "after" sollu;`, "after\n"},
+}
+
+func TestConformance(t *testing.T) {
+	for _, tt := range conformance {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := capture(t, func() {
+				evaluator.Eval(parse(tt.input), object.NewEnvironment())
+			})
+			machine := capture(t, func() {
+				comp := compiler.New()
+				if err := comp.Compile(parse(tt.input)); err != nil {
+					t.Fatalf("compile error: %s", err)
+				}
+				if err := vm.New(comp.Bytecode()).Run(); err != nil {
+					t.Fatalf("vm error: %s", err)
+				}
+			})
+			if eval != tt.output {
+				t.Errorf("evaluator output = %q, want %q", eval, tt.output)
+			}
+			if machine != eval {
+				t.Errorf("vm output = %q, evaluator output = %q", machine, eval)
+			}
+		})
+	}
+}
+
+// Programs stopped by an error, both backends print the same output
+// before it and stop with the same error.
+var stopping = []struct {
+	name   string
+	input  string
+	output string
+	err    string
+}{
+	{"non boolean loop condition", `yen i = 0; "before" sollu; i varaikkum { "never" sollu; } "after" sollu;`,
+		"before\n", "ERROR: Non Boolean Expression in Loop Condition"},
+	{"overflow in an assignment", `yen a = 4611686018427387904; a sollu; a = a * 2; "never" sollu;`,
+		"4611686018427387904\n", "ERROR: Integer overflow: 4611686018427387904 * 2"},
+	{"error in an assignment", `yen a = 1; a = a / 0; a sollu;`, "", "ERROR: Division by zero"},
+}
+
+func TestConformanceErrors(t *testing.T) {
+	for _, tt := range stopping {
+		t.Run(tt.name, func(t *testing.T) {
+			var evalErr, vmErr error
+			eval := capture(t, func() {
+				evalErr = (&evaluator.Interpreter{}).Run(parse(tt.input), object.NewEnvironment())
+			})
+			machine := capture(t, func() {
+				comp := compiler.New()
+				if err := comp.Compile(parse(tt.input)); err != nil {
+					t.Fatalf("compile error: %s", err)
+				}
+				vmErr = vm.New(comp.Bytecode()).Run()
+			})
+			if eval != tt.output || evalErr == nil || evalErr.Error() != tt.err {
+				t.Errorf("evaluator output = %q, error %v, want %q and %s", eval, evalErr, tt.output, tt.err)
+			}
+			if machine != eval || vmErr == nil || vmErr.Error() != evalErr.Error() {
+				t.Errorf("vm output = %q, error %v, evaluator output = %q, error %v", machine, vmErr, eval, evalErr)
+			}
+		})
+	}
+}
+
+func TestDeclarationErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`yen a = "x";`, "ERROR: Cannot Assign STRING to INTEGER variable"},
+		{`sol a = 1;`, "ERROR: Cannot Assign INTEGER to STRING variable"},
+		{`yen a = b;`, "ERROR: Unknown identifier"},
+	}
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compile error: %s", err)
+		}
+		err := vm.New(comp.Bytecode()).Run()
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
+
+func parse(input string) *tree.Program {
+	_, channel := lexer.CreateLexer([]byte(input), false)
+	return parser.Parse(channel, false)
+}
+
+// capture returns everything fn writes to stdout.
+func capture(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+	defer func() { os.Stdout = stdout }()
+	fn()
+	w.Close()
+	return <-done
+}
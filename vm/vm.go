@@ -0,0 +1,250 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iam-naveen/compiler/compiler"
+	"github.com/iam-naveen/compiler/evaluator"
+	"github.com/iam-naveen/compiler/lexer"
+	"github.com/iam-naveen/compiler/object"
+)
+
+const StackSize = 2048
+
+var (
+	True  = &object.Boolean{Value: true}
+	False = &object.Boolean{Value: false}
+)
+
+// The vm reuses the evaluator's operator semantics, keyed by the piece
+// the parser would have produced for the operator.
+var binaryOps = map[compiler.Opcode]lexer.Piece{
+	compiler.OpAdd:          {Kind: lexer.Plus, Value: "+"},
+	compiler.OpSub:          {Kind: lexer.Minus, Value: "-"},
+	compiler.OpMul:          {Kind: lexer.Star, Value: "*"},
+	compiler.OpDiv:          {Kind: lexer.Slash, Value: "/"},
+	compiler.OpMod:          {Kind: lexer.Percent, Value: "%"},
+	compiler.OpEqual:        {Kind: lexer.Equal, Value: "=="},
+	compiler.OpNotEqual:     {Kind: lexer.NotEqual, Value: "!="},
+	compiler.OpLess:         {Kind: lexer.Less, Value: "<"},
+	compiler.OpGreater:      {Kind: lexer.Greater, Value: ">"},
+	compiler.OpLessEqual:    {Kind: lexer.LessEqual, Value: "<="},
+	compiler.OpGreaterEqual: {Kind: lexer.GreaterEqual, Value: ">="},
+	compiler.OpAnd:          {Kind: lexer.And, Value: "&&"},
+	compiler.OpOr:           {Kind: lexer.Or, Value: "||"},
+}
+
+var prefixOps = map[compiler.Opcode]lexer.Piece{
+	compiler.OpMinus: {Kind: lexer.Minus, Value: "-"},
+	compiler.OpBang:  {Kind: lexer.Bang, Value: "!"},
+}
+
+type VM struct {
+	instructions compiler.Instructions
+	constants    []object.Object
+	names        []string
+	globals      []object.Object
+
+	stack []object.Object
+	sp    int // points to the next free slot, top of stack is stack[sp-1]
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return &VM{
+		instructions: bytecode.Instructions,
+		constants:    bytecode.Constants,
+		names:        bytecode.Globals,
+		globals:      make([]object.Object, len(bytecode.Globals)),
+		stack:        make([]object.Object, StackSize),
+	}
+}
+
+// Run executes the bytecode. The returned error carries the same message
+// the evaluator prints before terminating the program.
+func (vm *VM) Run() error {
+	ins := vm.instructions
+	for ip := 0; ip < len(ins); ip++ {
+		op := compiler.Opcode(ins[ip])
+		switch op {
+		case compiler.OpConstant:
+			index := compiler.ReadUint16(ins[ip+1:])
+			ip += 2
+			if err := vm.push(vm.constants[index]); err != nil {
+				return err
+			}
+		case compiler.OpTrue:
+			if err := vm.push(True); err != nil {
+				return err
+			}
+		case compiler.OpFalse:
+			if err := vm.push(False); err != nil {
+				return err
+			}
+
+		case compiler.OpGetGlobal:
+			index := compiler.ReadUint16(ins[ip+1:])
+			ip += 2
+			value := vm.globals[index]
+			if value == nil {
+				value = &object.Error{Message: "Unknown identifier"}
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case compiler.OpSetGlobal:
+			index := compiler.ReadUint16(ins[ip+1:])
+			ip += 2
+			value := vm.pop()
+			if value.Type() == object.ERROR_OBJ {
+				return errors.New(value.Inspect())
+			}
+			vm.globals[index] = value
+		case compiler.OpDeclare:
+			index := compiler.ReadUint16(ins[ip+1:])
+			datatype := compiler.DatatypeName(ins[ip+3])
+			ip += 3
+			value := vm.pop()
+			if value.Type() == object.ERROR_OBJ {
+				return errors.New(value.Inspect())
+			}
+			if datatype != string(value.Type()) {
+				return fmt.Errorf("ERROR: Cannot Assign %s to %s variable", value.Type(), datatype)
+			}
+			vm.globals[index] = value
+		case compiler.OpInput:
+			index := compiler.ReadUint16(ins[ip+1:])
+			datatype := compiler.DatatypeName(ins[ip+3])
+			prompt := vm.names[index] + " = "
+			if ins[ip+4] == 1 {
+				value := vm.pop()
+				if value.Type() == object.ERROR_OBJ {
+					return errors.New(value.Inspect())
+				}
+				prompt = value.Inspect()
+			}
+			ip += 4
+			vm.globals[index] = evaluator.ReadInput(prompt, datatype)
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv, compiler.OpMod,
+			compiler.OpEqual, compiler.OpNotEqual, compiler.OpLess, compiler.OpGreater,
+			compiler.OpLessEqual, compiler.OpGreaterEqual, compiler.OpAnd, compiler.OpOr:
+			right := vm.pop()
+			left := vm.pop()
+			if err := vm.push(vm.binary(op, left, right)); err != nil {
+				return err
+			}
+		case compiler.OpMinus, compiler.OpBang:
+			right := vm.pop()
+			if err := vm.push(evaluator.EvalPrefix(prefixOps[op], right)); err != nil {
+				return err
+			}
+		case compiler.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			if err := vm.push(evaluator.EvalIndex(left, index)); err != nil {
+				return err
+			}
+		case compiler.OpLength:
+			if err := vm.push(evaluator.EvalLength(vm.pop())); err != nil {
+				return err
+			}
+
+		case compiler.OpPrint:
+			count, newline := int(ins[ip+1]), ins[ip+2] == 1
+			ip += 2
+			values := make([]object.Object, count)
+			copy(values, vm.stack[vm.sp-count:vm.sp])
+			vm.sp -= count
+			fmt.Print(evaluator.Printed(values, newline))
+
+		case compiler.OpJump:
+			ip = int(compiler.ReadUint16(ins[ip+1:])) - 1
+		case compiler.OpJumpIfFalse:
+			target := int(compiler.ReadUint16(ins[ip+1:]))
+			ip += 2
+			condition, ok := vm.pop().(*object.Boolean)
+			if !ok {
+				return errors.New("ERROR: Non Boolean Expression in Loop Condition")
+			}
+			if !condition.Value {
+				ip = target - 1
+			}
+		case compiler.OpSkipIfNotBool:
+			target := int(compiler.ReadUint16(ins[ip+1:]))
+			ip += 2
+			if vm.stack[vm.sp-1].Type() != object.BOOLEAN_OBJ {
+				vm.pop()
+				err := &object.Error{Message: "Non Boolean Expression in If Statement"}
+				fmt.Println(err.Inspect())
+				ip = target - 1
+			}
+		case compiler.OpForPrep:
+			count, ok := vm.pop().(*object.Integer)
+			if !ok {
+				return errors.New("ERROR: Expected Constant Expression in For loop")
+			}
+			// the counter is private to the loop, so it is safe to mutate
+			if err := vm.push(&object.Integer{Value: count.Value}); err != nil {
+				return err
+			}
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpForNext:
+			target := int(compiler.ReadUint16(ins[ip+1:]))
+			ip += 2
+			counter := vm.stack[vm.sp-1].(*object.Integer)
+			if counter.Value <= 0 {
+				vm.pop()
+				ip = target - 1
+			} else {
+				counter.Value--
+			}
+
+		default:
+			return fmt.Errorf("ERROR: Unknown opcode %d", op)
+		}
+	}
+	return nil
+}
+
+func (vm *VM) binary(op compiler.Opcode, left, right object.Object) object.Object {
+	// fast path for the common integer comparisons, arithmetic is left to
+	// the evaluator, which checks for overflow
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		switch op {
+		case compiler.OpLess:
+			return nativeBool(l.Value < r.Value)
+		case compiler.OpGreater:
+			return nativeBool(l.Value > r.Value)
+		case compiler.OpLessEqual:
+			return nativeBool(l.Value <= r.Value)
+		case compiler.OpGreaterEqual:
+			return nativeBool(l.Value >= r.Value)
+		}
+	}
+	return evaluator.EvalBinary(binaryOps[op], left, right)
+}
+
+func nativeBool(value bool) *object.Boolean {
+	if value {
+		return True
+	}
+	return False
+}
+
+func (vm *VM) push(obj object.Object) error {
+	if vm.sp >= StackSize {
+		return errors.New("ERROR: Stack overflow")
+	}
+	vm.stack[vm.sp] = obj
+	vm.sp++
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	return vm.stack[vm.sp]
+}